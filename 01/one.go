@@ -7,40 +7,23 @@ import (
 	"github.com/bozdoz/advent-of-code-2021/utils"
 )
 
-func PartOne(nums []int) (int, error) {
-	last := nums[0]
-	count := 0
+// counts how many times the sum of a sliding window increases
+func countIncreases(nums []int, size int) int {
+	win := newWindow(size)
 
 	for _, val := range nums {
-		if val > last {
-			count += 1
-		}
-		last = val
+		win.push(val)
 	}
 
-	return count, nil
+	return win.increases
 }
 
-func PartTwo(nums []int) (int, error) {
-	measurement := 3
-	wins := [][]int{}
-	max := len(nums) - measurement + 1
-
-	for i := range nums[:max] {
-		wins = append(wins, nums[i:i+measurement])
-	}
-
-	last := utils.Sum(wins[0]...)
-	count := 0
-	for _, arr := range wins[1:] {
-		val := utils.Sum(arr...)
-		if val > last {
-			count += 1
-		}
-		last = val
-	}
+func PartOne(nums []int) (int, error) {
+	return countIncreases(nums, 1), nil
+}
 
-	return count, nil
+func PartTwo(nums []int) (int, error) {
+	return countIncreases(nums, 3), nil
 }
 
 func main() {
@@ -50,6 +33,20 @@ func main() {
 	}
 
 	filename := os.Args[1]
+
+	// "-" streams readings from stdin through a window of 3,
+	// without ever loading them all into memory
+	if filename == "-" {
+		win := newWindow(3)
+
+		if err := win.scan(os.Stdin); err != nil {
+			panic(err)
+		}
+
+		fmt.Println(win)
+		return
+	}
+
 	nums := utils.LoadInts(filename)
 
 	answer, err := PartOne(nums)
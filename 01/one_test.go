@@ -1,6 +1,8 @@
 package main
 
 import (
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
@@ -48,3 +50,74 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestWindowStats(t *testing.T) {
+	vals := utils.LoadInts("example.txt")
+	win := newWindow(3)
+
+	for _, val := range vals {
+		win.push(val)
+	}
+
+	// last window is 269, 260, 263
+	if win.min() != 260 || win.max() != 269 {
+		t.Logf("min/max should be 260/269, but got %d/%d", win.min(), win.max())
+		t.Fail()
+	}
+
+	if win.mean() != 264 {
+		t.Logf("mean should be 264, but got %f", win.mean())
+		t.Fail()
+	}
+
+	expected := utils.Stdev([]int{269, 260, 263})
+
+	if math.Abs(win.stdev()-expected) > 1e-9 {
+		t.Logf("stdev should be %f, but got %f", expected, win.stdev())
+		t.Fail()
+	}
+
+	if win.increases != 5 || win.decreases != 1 {
+		t.Logf("increases/decreases should be 5/1, but got %d/%d", win.increases, win.decreases)
+		t.Fail()
+	}
+}
+
+func TestWindowStream(t *testing.T) {
+	win := newWindow(1)
+	err := win.scan(strings.NewReader("3\n1\n2\n"))
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+	}
+
+	if win.increases != 1 || win.decreases != 1 {
+		t.Logf("increases/decreases should be 1/1, but got %d/%d", win.increases, win.decreases)
+		t.Fail()
+	}
+
+	err = win.scan(strings.NewReader("4\nfive\n"))
+
+	if err == nil {
+		t.Log("expected an error for a non-integer line")
+		t.Fail()
+	}
+}
+
+func TestWindowStdevLargeReadings(t *testing.T) {
+	win := newWindow(3)
+	base := 3_000_000_000
+
+	// a sum of squares would be way past what float64 can tell apart
+	for _, val := range []int{base + 100, base + 7, base + 1, base + 2, base + 3} {
+		win.push(val)
+	}
+
+	expected := math.Sqrt(2.0 / 3.0)
+
+	if math.Abs(win.stdev()-expected) > 1e-6 {
+		t.Logf("stdev should be %f, but got %f", expected, win.stdev())
+		t.Fail()
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// a monotonic queue entry: the value and when it was pushed
+type entry struct {
+	index int
+	value int
+}
+
+// Window keeps rolling statistics over the last `size` values of a stream,
+// without ever holding more than `size` values in memory
+type Window struct {
+	size   int
+	values []int // ring buffer
+	// total number of values pushed
+	count int
+	// running sum of the values currently in the window
+	sum int
+	// Welford's running mean and sum of squared differences from it,
+	// which doesn't lose precision (or overflow) like a sum of squares
+	runningMean float64
+	m2          float64
+	// sum of the previous full window, used for increases/decreases
+	lastSum   int
+	increases int
+	decreases int
+	// monotonic queues for O(1) amortized min/max
+	mins []entry
+	maxs []entry
+}
+
+// constructor-like function to create a Window
+func newWindow(size int) *Window {
+	if size < 1 {
+		panic("window size must be at least 1")
+	}
+
+	return &Window{
+		size:   size,
+		values: make([]int, size),
+	}
+}
+
+// adds a value to the window, evicting the oldest if the window is full
+func (win *Window) push(val int) {
+	slot := win.count % win.size
+
+	if win.count >= win.size {
+		old := win.values[slot]
+		win.sum -= old
+		win.removeMoment(old, win.size-1)
+	}
+
+	win.values[slot] = val
+	win.sum += val
+	win.addMoment(val, utils.MinInt(win.count, win.size-1)+1)
+
+	win.pushMin(val)
+	win.pushMax(val)
+
+	win.count++
+
+	if win.isFull() {
+		// compare to previous full window only
+		if win.count > win.size {
+			if win.sum > win.lastSum {
+				win.increases++
+			} else if win.sum < win.lastSum {
+				win.decreases++
+			}
+		}
+
+		win.lastSum = win.sum
+	}
+}
+
+// Welford's update, for a window that now holds n values
+func (win *Window) addMoment(val, n int) {
+	delta := float64(val) - win.runningMean
+	win.runningMean += delta / float64(n)
+	win.m2 += delta * (float64(val) - win.runningMean)
+}
+
+// and in reverse, for a window that's down to n values
+func (win *Window) removeMoment(val, n int) {
+	if n == 0 {
+		win.runningMean, win.m2 = 0, 0
+		return
+	}
+
+	delta := float64(val) - win.runningMean
+	win.runningMean -= delta / float64(n)
+	win.m2 -= delta * (float64(val) - win.runningMean)
+}
+
+// drops values from the queue that can never be the min again
+func (win *Window) pushMin(val int) {
+	for len(win.mins) > 0 && win.mins[len(win.mins)-1].value >= val {
+		win.mins = win.mins[:len(win.mins)-1]
+	}
+
+	win.mins = append(win.mins, entry{win.count, val})
+
+	// drop anything that slid out of the window
+	if win.mins[0].index <= win.count-win.size {
+		win.mins = win.mins[1:]
+	}
+}
+
+// drops values from the queue that can never be the max again
+func (win *Window) pushMax(val int) {
+	for len(win.maxs) > 0 && win.maxs[len(win.maxs)-1].value <= val {
+		win.maxs = win.maxs[:len(win.maxs)-1]
+	}
+
+	win.maxs = append(win.maxs, entry{win.count, val})
+
+	if win.maxs[0].index <= win.count-win.size {
+		win.maxs = win.maxs[1:]
+	}
+}
+
+// whether the window has seen at least `size` values
+func (win *Window) isFull() bool {
+	return win.count >= win.size
+}
+
+// number of values currently in the window
+func (win *Window) len() int {
+	if win.isFull() {
+		return win.size
+	}
+
+	return win.count
+}
+
+func (win *Window) mean() float64 {
+	if win.count == 0 {
+		return 0
+	}
+
+	return float64(win.sum) / float64(win.len())
+}
+
+func (win *Window) min() int {
+	if win.count == 0 {
+		return 0
+	}
+
+	return win.mins[0].value
+}
+
+func (win *Window) max() int {
+	if win.count == 0 {
+		return 0
+	}
+
+	return win.maxs[0].value
+}
+
+// population standard deviation of the window (same as utils.Stdev)
+func (win *Window) stdev() float64 {
+	if win.count == 0 {
+		return 0
+	}
+
+	// guard against tiny negative values from float error
+	variance := math.Max(win.m2, 0) / float64(win.len())
+
+	return math.Sqrt(variance)
+}
+
+// reads one int per line from any stream (file, stdin, socket...)
+// and pushes each one through the window
+func (win *Window) scan(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" {
+			continue
+		}
+
+		val, err := strconv.Atoi(text)
+
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		win.push(val)
+	}
+
+	return scanner.Err()
+}
+
+// custom string representation
+func (win *Window) String() string {
+	return fmt.Sprintf(
+		"Window(%d) { increases: %d, decreases: %d, mean: %.2f, min: %d, max: %d, stdev: %.2f }",
+		win.size,
+		win.increases,
+		win.decreases,
+		win.mean(),
+		win.min(),
+		win.max(),
+		win.stdev(),
+	)
+}
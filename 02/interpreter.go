@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrBadArgument    = errors.New("bad argument")
+)
+
+// where the submarine is after any number of commands
+type Submarine struct {
+	position, depth, aim int
+}
+
+// a Handler moves the submarine by some amount
+type Handler func(sub *Submarine, val int)
+
+// a Model maps command names to handlers;
+// swapping models changes what the same commands mean
+type Model map[string]Handler
+
+// Part One: up/down change depth directly
+func plainModel() Model {
+	return Model{
+		"forward": func(sub *Submarine, val int) {
+			sub.position += val
+		},
+		"backward": func(sub *Submarine, val int) {
+			sub.position -= val
+		},
+		"up": func(sub *Submarine, val int) {
+			sub.depth -= val
+		},
+		"down": func(sub *Submarine, val int) {
+			sub.depth += val
+		},
+	}
+}
+
+// Part Two: up/down change aim, and moving changes depth by aim
+func aimModel() Model {
+	return Model{
+		"forward": func(sub *Submarine, val int) {
+			sub.position += val
+			sub.depth += val * sub.aim
+		},
+		"backward": func(sub *Submarine, val int) {
+			sub.position -= val
+			sub.depth -= val * sub.aim
+		},
+		"up": func(sub *Submarine, val int) {
+			sub.aim -= val
+		},
+		"down": func(sub *Submarine, val int) {
+			sub.aim += val
+		},
+	}
+}
+
+// CommandError points to the line that couldn't be run
+type CommandError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (err *CommandError) Error() string {
+	return fmt.Sprintf("line %d: %v: %q", err.Line, err.Err, err.Text)
+}
+
+func (err *CommandError) Unwrap() error {
+	return err.Err
+}
+
+type Interpreter struct {
+	model Model
+	sub   Submarine
+	// every state after each command, starting at the origin
+	trajectory []Submarine
+}
+
+// constructor-like function to create an Interpreter
+func newInterpreter(model Model) *Interpreter {
+	return &Interpreter{
+		model:      model,
+		trajectory: []Submarine{{}},
+	}
+}
+
+// adds or replaces a command
+func (interp *Interpreter) register(name string, handler Handler) {
+	interp.model[name] = handler
+}
+
+// parses a single "command value" line
+func (interp *Interpreter) parse(text string) (Handler, int, error) {
+	fields := strings.Fields(text)
+
+	if len(fields) == 0 {
+		return nil, 0, ErrUnknownCommand
+	}
+
+	handler, ok := interp.model[fields[0]]
+
+	if !ok {
+		return nil, 0, ErrUnknownCommand
+	}
+
+	if len(fields) != 2 {
+		return nil, 0, ErrBadArgument
+	}
+
+	val, err := strconv.Atoi(fields[1])
+
+	if err != nil {
+		return nil, 0, ErrBadArgument
+	}
+
+	return handler, val, nil
+}
+
+// runs every line, stopping at the first one that fails
+func (interp *Interpreter) run(lines []string) error {
+	for i, text := range lines {
+		// ignore blank lines
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		handler, val, err := interp.parse(text)
+
+		if err != nil {
+			return &CommandError{
+				Line: i + 1,
+				Text: text,
+				Err:  err,
+			}
+		}
+
+		handler(&interp.sub, val)
+		interp.trajectory = append(interp.trajectory, interp.sub)
+	}
+
+	return nil
+}
+
+// the puzzle answer
+func (interp *Interpreter) product() int {
+	return interp.sub.position * interp.sub.depth
+}
+
+// writes every step of the trajectory, for plotting
+func (interp *Interpreter) writeCSV(writer io.Writer) error {
+	w := csv.NewWriter(writer)
+
+	if err := w.Write([]string{"step", "position", "depth", "aim"}); err != nil {
+		return err
+	}
+
+	for step, sub := range interp.trajectory {
+		record := []string{
+			strconv.Itoa(step),
+			strconv.Itoa(sub.position),
+			strconv.Itoa(sub.depth),
+			strconv.Itoa(sub.aim),
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
@@ -3,76 +3,26 @@ package main
 import (
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
 )
 
-func withVals(vals []string, fnc func(direction string, val int)) error {
-	for _, val := range vals {
-		v := strings.Fields(val)
-		num, err := strconv.Atoi(v[1])
+func runModel(vals []string, model Model) (int, error) {
+	interp := newInterpreter(model)
 
-		if err != nil {
-			return err
-		}
-
-		fnc(v[0], num)
+	if err := interp.run(vals); err != nil {
+		return -1, err
 	}
 
-	return nil
+	return interp.product(), nil
 }
 
 func PartOne(vals []string) (int, error) {
-	horizontal := 0
-	depth := 0
-
-	err := withVals(vals, func(direction string, val int) {
-		switch direction {
-		case "forward":
-			horizontal += val
-		case "backward":
-			horizontal -= val
-		case "up":
-			depth -= val
-		case "down":
-			depth += val
-		}
-	})
-
-	if err != nil {
-		return -1, err
-	}
-
-	return horizontal * depth, nil
+	return runModel(vals, plainModel())
 }
 
 func PartTwo(vals []string) (int, error) {
-	horizontal := 0
-	depth := 0
-	aim := 0
-
-	err := withVals(vals, func(direction string, val int) {
-		switch direction {
-		case "forward":
-			horizontal += val
-			depth += val * aim
-		case "backward":
-			horizontal -= val
-			depth -= val * aim
-		case "up":
-			aim -= val
-		case "down":
-			aim += val
-		}
-	})
-
-	if err != nil {
-		return -1, err
-	}
-
-	return horizontal * depth, nil
+	return runModel(vals, aimModel())
 }
 
 func main() {
@@ -99,4 +49,34 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	// optionally write the Part Two trajectory to a csv file
+	if len(os.Args) > 2 {
+		if err := writeTrajectory(vals, os.Args[2]); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func writeTrajectory(vals []string, filename string) error {
+	interp := newInterpreter(aimModel())
+
+	if err := interp.run(vals); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return err
+	}
+
+	err = interp.writeCSV(file)
+
+	// a failed close can mean the rows never made it to disk
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
@@ -37,3 +39,60 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestUnknownCommand(t *testing.T) {
+	vals := []string{"forward 5", "sideways 2"}
+	_, err := PartOne(vals)
+
+	var cmdErr *CommandError
+
+	if !errors.As(err, &cmdErr) || !errors.Is(err, ErrUnknownCommand) {
+		t.Log("error should be an unknown CommandError", err)
+		t.FailNow()
+	}
+
+	if cmdErr.Line != 2 {
+		t.Logf("error should be on line %d, but got %d", 2, cmdErr.Line)
+		t.Fail()
+	}
+}
+
+func TestBadArgument(t *testing.T) {
+	vals := []string{"forward five"}
+	_, err := PartTwo(vals)
+
+	if !errors.Is(err, ErrBadArgument) {
+		t.Log("error should be a bad argument", err)
+		t.Fail()
+	}
+}
+
+func TestRegisterAndTrajectory(t *testing.T) {
+	interp := newInterpreter(aimModel())
+	interp.register("surface", func(sub *Submarine, val int) {
+		sub.depth = 0
+		sub.aim = 0
+	})
+
+	err := interp.run([]string{"down 2", "forward 3", "surface 0"})
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+	}
+
+	var out strings.Builder
+	interp.writeCSV(&out)
+
+	expected := `step,position,depth,aim
+0,0,0,0
+1,0,0,2
+2,3,6,2
+3,3,0,0
+`
+
+	if out.String() != expected {
+		t.Logf("Answer should be %q, but got %q", expected, out.String())
+		t.Fail()
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+const wordSize = 64
+
+// Report holds each line as packed bits; lines can be any width,
+// spanning as many uint64 words as they need
+type Report struct {
+	width int
+	rows  int
+	// words per row
+	stride int
+	// row r, column c is bit c%64 of words[r*stride + c/64]
+	// (column 0 is the line's leftmost character, in bit 0 of its word)
+	words []uint64
+}
+
+// constructor-like function to parse a Report
+func newReport(lines []string) (*Report, error) {
+	report := &Report{}
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		if report.width == 0 {
+			report.width = len(line)
+			report.stride = (report.width + wordSize - 1) / wordSize
+		} else if len(line) != report.width {
+			return nil, fmt.Errorf("line %d: expected %d bits, got %d", i+1, report.width, len(line))
+		}
+
+		if report.words == nil {
+			report.words = make([]uint64, len(lines)*report.stride)
+		}
+
+		row := report.row(report.rows)
+
+		// built up a word at a time, rather than poking at the slice
+		// for every character
+		for w := range row {
+			var word uint64
+			end := (w + 1) * wordSize
+
+			if end > len(line) {
+				end = len(line)
+			}
+
+			for c := w * wordSize; c < end; c++ {
+				b := line[c] - '0'
+
+				if b > 1 {
+					return nil, fmt.Errorf("line %d: invalid bit %q", i+1, line[c])
+				}
+
+				word |= uint64(b) << (c % wordSize)
+			}
+
+			row[w] = word
+		}
+
+		report.rows++
+	}
+
+	if report.rows == 0 {
+		return nil, errors.New("report is empty")
+	}
+
+	// blank lines were skipped
+	report.words = report.words[:report.rows*report.stride]
+
+	return report, nil
+}
+
+// the words for a single row
+func (report *Report) row(r int) []uint64 {
+	return report.words[r*report.stride : (r+1)*report.stride]
+}
+
+func (report *Report) bit(r, c int) int {
+	return int(report.words[r*report.stride+c/wordSize]>>(c%wordSize)) & 1
+}
+
+// how many 1s are in each column, in a single pass over the words
+func (report *Report) columnCounts() []int {
+	counts := make([]int, report.width)
+
+	for i, word := range report.words {
+		offset := (i % report.stride) * wordSize
+
+		// only visit the set bits
+		for word != 0 {
+			counts[offset+bits.TrailingZeros64(word)]++
+			word &= word - 1
+		}
+	}
+
+	return counts
+}
+
+// most common bit per column (1 on ties), and its complement
+func (report *Report) gammaEpsilon() (gamma, epsilon []uint64) {
+	gamma = make([]uint64, report.stride)
+	epsilon = make([]uint64, report.stride)
+
+	for c, count := range report.columnCounts() {
+		if count*2 >= report.rows {
+			gamma[c/wordSize] |= 1 << (c % wordSize)
+		} else {
+			epsilon[c/wordSize] |= 1 << (c % wordSize)
+		}
+	}
+
+	return
+}
+
+// converts packed bits back to a number, if it fits in an int
+func (report *Report) toInt(words []uint64) (int, error) {
+	if report.width >= bits.UintSize {
+		return -1, fmt.Errorf("%d bits is too wide for an int", report.width)
+	}
+
+	out := 0
+
+	for c := 0; c < report.width; c++ {
+		bit := int(words[c/wordSize]>>(c%wordSize)) & 1
+		out = out<<1 | bit
+	}
+
+	return out, nil
+}
+
+// narrows the rows down one column at a time, letting `keep` pick
+// which bit survives given how many 0s and 1s are left; each column
+// only looks at the rows still standing, so it's about 2 passes over
+// the report in total
+func (report *Report) rating(keep func(zeros, ones int) int) int {
+	candidates := make([]int, report.rows)
+
+	for r := range candidates {
+		candidates[r] = r
+	}
+
+	for c := 0; c < report.width && len(candidates) > 1; c++ {
+		offset, shift := c/wordSize, c%wordSize
+		ones := 0
+
+		for _, r := range candidates {
+			ones += int(report.words[r*report.stride+offset]>>shift) & 1
+		}
+
+		zeros := len(candidates) - ones
+
+		// every row left has the same bit here, so there's no choice
+		if zeros == 0 || ones == 0 {
+			continue
+		}
+
+		bit := keep(zeros, ones)
+		kept := candidates[:0]
+
+		for _, r := range candidates {
+			if int(report.words[r*report.stride+offset]>>shift)&1 == bit {
+				kept = append(kept, r)
+			}
+		}
+
+		candidates = kept
+	}
+
+	return candidates[0]
+}
+
+// most common bit, 1 on ties
+func (report *Report) oxygen() int {
+	return report.rating(func(zeros, ones int) int {
+		if ones >= zeros {
+			return 1
+		}
+		return 0
+	})
+}
+
+// least common bit, 0 on ties
+func (report *Report) co2() int {
+	return report.rating(func(zeros, ones int) int {
+		if zeros <= ones {
+			return 0
+		}
+		return 1
+	})
+}
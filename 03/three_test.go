@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
@@ -37,3 +40,60 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestWideReport(t *testing.T) {
+	// 70 bits: spans two words
+	one := strings.Repeat("0", 69) + "1"
+	two := "1" + strings.Repeat("0", 68) + "1"
+
+	report, err := newReport([]string{one, two})
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	counts := report.columnCounts()
+
+	if counts[0] != 1 || counts[1] != 0 || counts[69] != 2 {
+		t.Logf("unexpected column counts %v", counts)
+		t.Fail()
+	}
+
+	if _, err := report.toInt(report.row(0)); err == nil {
+		t.Log("expected an error converting 70 bits to an int")
+		t.Fail()
+	}
+}
+
+func TestInvalidReport(t *testing.T) {
+	_, err := newReport([]string{"0101", "012"})
+
+	if err == nil {
+		t.Log("expected an error for a mismatched width")
+		t.Fail()
+	}
+
+	_, err = newReport([]string{"0101", "01a1"})
+
+	if err == nil {
+		t.Log("expected an error for an invalid bit")
+		t.Fail()
+	}
+}
+
+func BenchmarkLargeReport(b *testing.B) {
+	random := rand.New(rand.NewSource(3))
+	lines := make([]string, 100000)
+
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%020b", random.Intn(1<<20))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		PartOne(lines)
+		PartTwo(lines)
+	}
+}
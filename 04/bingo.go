@@ -0,0 +1,274 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// a cell on a board
+type Cell struct {
+	row, col int
+}
+
+// a Pattern wins when every one of its cells is marked
+type Pattern []Cell
+
+// a WinRule lists the winning patterns for a board of a given size
+type WinRule func(rows, cols int) []Pattern
+
+func Rows(rows, cols int) (patterns []Pattern) {
+	for r := 0; r < rows; r++ {
+		pattern := Pattern{}
+
+		for c := 0; c < cols; c++ {
+			pattern = append(pattern, Cell{r, c})
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return
+}
+
+func Columns(rows, cols int) (patterns []Pattern) {
+	for c := 0; c < cols; c++ {
+		pattern := Pattern{}
+
+		for r := 0; r < rows; r++ {
+			pattern = append(pattern, Cell{r, c})
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return
+}
+
+// both diagonals; only square boards have them
+func Diagonals(rows, cols int) []Pattern {
+	if rows != cols {
+		return nil
+	}
+
+	down, up := Pattern{}, Pattern{}
+
+	for i := 0; i < rows; i++ {
+		down = append(down, Cell{i, i})
+		up = append(up, Cell{rows - 1 - i, i})
+	}
+
+	return []Pattern{down, up}
+}
+
+func Corners(rows, cols int) []Pattern {
+	return []Pattern{{
+		{0, 0},
+		{0, cols - 1},
+		{rows - 1, 0},
+		{rows - 1, cols - 1},
+	}}
+}
+
+// every cell
+func Blackout(rows, cols int) []Pattern {
+	pattern := Pattern{}
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			pattern = append(pattern, Cell{r, c})
+		}
+	}
+
+	return []Pattern{pattern}
+}
+
+type Board struct {
+	rows, cols int
+	cells      [][]int
+	marked     [][]bool
+	// patterns that each cell belongs to, indexed [row][col]
+	cellPatterns [][][]int
+	// unmarked cells left in each pattern
+	remaining []int
+	// kept up to date while marking, for O(1) scoring
+	unmarkedSum int
+	// a pattern is complete, but the board hasn't been scored yet
+	completed bool
+	won       bool
+}
+
+// a board number at a specific cell
+type Position struct {
+	board int
+	Cell
+}
+
+// Win is a board winning, in the order they happened
+type Win struct {
+	Board int
+	Call  int
+	Score int
+}
+
+type Game struct {
+	numbers []int
+	boards  []*Board
+	// every position of every number, so calling is O(1) per hit
+	index map[int][]Position
+}
+
+// bingo board
+func newBoard(boardStr string, rules []WinRule) (*Board, error) {
+	board := &Board{}
+
+	for _, line := range strings.Split(strings.TrimSpace(boardStr), "\n") {
+		fields := strings.Fields(line)
+		row := make([]int, len(fields))
+
+		if board.cols == 0 {
+			board.cols = len(fields)
+		} else if len(fields) != board.cols {
+			return nil, fmt.Errorf("board row %d has %d numbers, expected %d", board.rows+1, len(fields), board.cols)
+		}
+
+		for c, val := range fields {
+			num, err := strconv.Atoi(val)
+
+			if err != nil {
+				return nil, err
+			}
+
+			row[c] = num
+			board.unmarkedSum += num
+		}
+
+		board.cells = append(board.cells, row)
+		board.marked = append(board.marked, make([]bool, board.cols))
+		board.rows++
+	}
+
+	if board.rows == 0 || board.cols == 0 {
+		return nil, errors.New("board is empty")
+	}
+
+	board.cellPatterns = make([][][]int, board.rows)
+
+	for r := range board.cellPatterns {
+		board.cellPatterns[r] = make([][]int, board.cols)
+	}
+
+	for _, rule := range rules {
+		for _, pattern := range rule(board.rows, board.cols) {
+			id := len(board.remaining)
+			board.remaining = append(board.remaining, len(pattern))
+
+			for _, cell := range pattern {
+				board.cellPatterns[cell.row][cell.col] = append(board.cellPatterns[cell.row][cell.col], id)
+			}
+		}
+	}
+
+	return board, nil
+}
+
+// marks a cell and reports whether that completed any pattern
+func (board *Board) mark(cell Cell) (completed bool) {
+	if board.marked[cell.row][cell.col] {
+		return false
+	}
+
+	board.marked[cell.row][cell.col] = true
+	board.unmarkedSum -= board.cells[cell.row][cell.col]
+
+	for _, id := range board.cellPatterns[cell.row][cell.col] {
+		board.remaining[id]--
+
+		if board.remaining[id] == 0 {
+			completed = true
+		}
+	}
+
+	return
+}
+
+// constructor-like function to parse a Game: the first block is
+// the numbers to call, every other block is a board of any size;
+// with no rules given, rows and columns win (the puzzle rules)
+func newGame(content string, rules ...WinRule) (*Game, error) {
+	if len(rules) == 0 {
+		rules = []WinRule{Rows, Columns}
+	}
+
+	parts := utils.SplitByEmptyNewline(content)
+	game := &Game{
+		index: map[int][]Position{},
+	}
+
+	for _, val := range strings.Split(parts[0], ",") {
+		num, err := strconv.Atoi(strings.TrimSpace(val))
+
+		if err != nil {
+			return nil, err
+		}
+
+		game.numbers = append(game.numbers, num)
+	}
+
+	for b, part := range parts[1:] {
+		board, err := newBoard(part, rules)
+
+		if err != nil {
+			return nil, fmt.Errorf("board %d: %w", b+1, err)
+		}
+
+		for r, row := range board.cells {
+			for c, num := range row {
+				game.index[num] = append(game.index[num], Position{b, Cell{r, c}})
+			}
+		}
+
+		game.boards = append(game.boards, board)
+	}
+
+	return game, nil
+}
+
+// calls every number and returns every board that won, in order
+func (game *Game) play() (wins []Win) {
+	for _, num := range game.numbers {
+		positions := game.index[num]
+
+		for _, pos := range positions {
+			board := game.boards[pos.board]
+
+			if !board.won && board.mark(pos.Cell) {
+				board.completed = true
+			}
+		}
+
+		// a board can have the same number twice, so only score
+		// once every position of this number is marked
+		for _, pos := range positions {
+			board := game.boards[pos.board]
+
+			if board.completed && !board.won {
+				board.won = true
+				wins = append(wins, Win{
+					Board: pos.board,
+					Call:  num,
+					Score: board.unmarkedSum * num,
+				})
+			}
+		}
+
+		if len(wins) == len(game.boards) {
+			break
+		}
+	}
+
+	return
+}
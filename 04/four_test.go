@@ -37,3 +37,93 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestFinishingOrder(t *testing.T) {
+	game, err := newGame(utils.LoadAsString("example.txt"))
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	wins := game.play()
+	expected := []Win{
+		{Board: 2, Call: 24, Score: 4512},
+		{Board: 0, Call: 16, Score: 2192},
+		{Board: 1, Call: 13, Score: 1924},
+	}
+
+	if len(wins) != len(expected) {
+		t.Logf("Answer should be %v, but got %v", expected, wins)
+		t.FailNow()
+	}
+
+	for i, win := range wins {
+		if win != expected[i] {
+			t.Logf("Win %d should be %v, but got %v", i, expected[i], win)
+			t.Fail()
+		}
+	}
+}
+
+func TestRectangularBoardsAndRules(t *testing.T) {
+	content := `5,1,9,3,7
+
+1 2 3
+4 5 6
+7 8 9
+
+1 2 3 4
+5 6 7 8`
+
+	t.Run("Diagonals", func(t *testing.T) {
+		game, _ := newGame(content, Diagonals)
+		wins := game.play()
+
+		// the 2x4 board has no diagonals
+		if len(wins) != 1 || wins[0].Board != 0 || wins[0].Call != 9 {
+			t.Logf("Answer should be a win on board 0 at 9, but got %v", wins)
+			t.Fail()
+		}
+	})
+
+	t.Run("Corners", func(t *testing.T) {
+		game, _ := newGame(content, Corners)
+		wins := game.play()
+
+		if len(wins) != 1 || wins[0].Board != 0 || wins[0].Call != 7 {
+			t.Logf("Answer should be a win on board 0 at 7, but got %v", wins)
+			t.Fail()
+		}
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		game, _ := newGame(content, Columns)
+		wins := game.play()
+
+		// 5 and 1 make a column on the 2x4 board
+		if len(wins) != 1 || wins[0].Board != 1 || wins[0].Score != (2+3+4+6+7+8)*1 {
+			t.Logf("Answer should be a win on board 1 at 1, but got %v", wins)
+			t.Fail()
+		}
+	})
+
+	t.Run("Blackout", func(t *testing.T) {
+		game, _ := newGame(content, Blackout)
+		wins := game.play()
+
+		if len(wins) != 0 {
+			t.Logf("No board should black out, but got %v", wins)
+			t.Fail()
+		}
+	})
+}
+
+func TestRaggedBoard(t *testing.T) {
+	_, err := newGame("1,2\n\n1 2\n3")
+
+	if err == nil {
+		t.Log("expected an error for a ragged board")
+		t.Fail()
+	}
+}
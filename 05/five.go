@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
@@ -12,174 +14,160 @@ type line struct {
 	from, to coords
 }
 
+// how overlaps are counted
+type Mode int
+
+const (
+	// draw every line with Bresenham and count the cells
+	Raster Mode = iota
+	// no drawing: intersect segments, counting integer points only
+	Lattice
+	// no drawing: intersect segments, counting every crossing point,
+	// even ones between integer coordinates
+	Exact
+)
+
+// coordinates must stay small enough that intersection math can't overflow
+const maxCoord = 1 << 30
+
 type grid struct {
-	// 1d representation of 2d space
-	space         []int
-	width         int
-	lines         []line
-	checkDiagonal bool
+	// sparse: only cells that lines pass through
+	space map[coords]int
+	lines []line
+	// bounding box of every line
+	min, max coords
+	// which lines are drawn/intersected
+	include LineKind
 }
 
 func (g *grid) load(data []string) (err error) {
-	maxX := 0
-	maxY := 0
+	for i, row := range data {
+		if row == "" {
+			continue
+		}
 
-	for _, row := range data {
 		var x1, y1, x2, y2 int
 
 		_, err := fmt.Sscanf(row, "%d,%d -> %d,%d", &x1, &y1, &x2, &y2)
 
 		if err != nil {
-			return err
+			return fmt.Errorf("line %d: %w", i+1, err)
 		}
 
-		g.lines = append(g.lines, line{
-			from: [2]int{x1, y1},
-			to:   [2]int{x2, y2},
-		})
-
-		if x1 > maxX {
-			maxX = x1
+		for _, val := range [...]int{x1, y1, x2, y2} {
+			if utils.Abs(val) >= maxCoord {
+				return fmt.Errorf("line %d: coordinate %d is out of range", i+1, val)
+			}
 		}
 
-		if x2 > maxX {
-			maxX = x2
+		l := line{
+			from: coords{x1, y1},
+			to:   coords{x2, y2},
 		}
 
-		if x1 > maxX {
-			maxX = x1
+		if len(g.lines) == 0 {
+			g.min = l.from
+			g.max = l.from
 		}
 
-		if y1 > maxY {
-			maxY = y1
-		}
+		g.min[0] = utils.MinInt(g.min[0], x1, x2)
+		g.min[1] = utils.MinInt(g.min[1], y1, y2)
+		g.max[0] = utils.MaxInt(g.max[0], x1, x2)
+		g.max[1] = utils.MaxInt(g.max[1], y1, y2)
 
-		if y2 > maxY {
-			maxY = y2
-		}
+		g.lines = append(g.lines, l)
 	}
 
-	g.width = maxX + 1
-	g.space = make([]int, g.width*(maxY+1))
+	if len(g.lines) == 0 {
+		return errors.New("no lines to draw")
+	}
 
 	return
 }
 
 func (g *grid) draw(x, y int) {
-	g.space[g.width*y+x]++
+	g.space[coords{x, y}]++
 }
 
-func (g *grid) drawVertical(l line) {
-	x := l.from[0]
-	y := l.from[1]
-	y2 := l.to[1]
-
-	// beginning coord should be counted
-	g.draw(x, y)
+func (g *grid) drawLines() {
+	g.space = map[coords]int{}
 
-	for y != y2 {
-		if y < y2 {
-			y++
-		} else {
-			y--
+	for _, l := range g.lines {
+		if g.include&l.kind() != 0 {
+			l.rasterize(g.draw)
 		}
-		g.draw(x, y)
 	}
 }
 
-func (g *grid) drawHorizontal(l line) {
-	y := l.from[1]
-	x := l.from[0]
-	x2 := l.to[0]
-
-	// beginning coord should be counted
-	g.draw(x, y)
-
-	for x != x2 {
-		if x < x2 {
-			x++
-		} else {
-			x--
+// cells where at least two lines overlap
+func (g *grid) overlaps() (output int) {
+	for _, num := range g.space {
+		if num > 1 {
+			output++
 		}
-		g.draw(x, y)
 	}
-}
-
-func (g *grid) drawDiagonal(l line) {
-	y := l.from[1]
-	x := l.from[0]
-	x2 := l.to[0]
-	y2 := l.to[1]
-
-	// beginning coord should be counted
-	g.draw(x, y)
 
-	for x != x2 {
-		if x < x2 {
-			x++
-		} else {
-			x--
-		}
+	return
+}
 
-		if y < y2 {
-			y++
-		} else {
-			y--
-		}
+// which mode PartOne and PartTwo use (set with -mode)
+var overlapMode = Raster
 
-		g.draw(x, y)
+func countOverlaps(content []string, include LineKind) (int, error) {
+	grid := grid{
+		include: include,
 	}
-}
 
-func (g *grid) drawLines() {
-	for _, l := range g.lines {
-		// only draw horizontal/vertical lines
-		if l.from[0] == l.to[0] {
-			g.drawVertical(l)
-		} else if l.from[1] == l.to[1] {
-			g.drawHorizontal(l)
-		} else if g.checkDiagonal {
-			g.drawDiagonal(l)
-		}
+	if err := grid.load(content); err != nil {
+		return -1, err
 	}
-}
 
-func PartOne(content []string) (output int, err error) {
-	grid := grid{}
+	switch overlapMode {
+	case Lattice:
+		return grid.intersections(false), nil
+	case Exact:
+		return grid.intersections(true), nil
+	}
 
-	grid.load(content)
 	grid.drawLines()
 
-	for _, num := range grid.space {
-		if num > 1 {
-			output++
-		}
-	}
+	return grid.overlaps(), nil
+}
 
-	return
+// only horizontal/vertical lines
+func PartOne(content []string) (output int, err error) {
+	return countOverlaps(content, Horizontal|Vertical)
 }
 
+// include 45 degree diagonals
 func PartTwo(content []string) (output int, err error) {
-	grid := grid{
-		checkDiagonal: true,
-	}
-
-	grid.load(content)
-	grid.drawLines()
-
-	for _, num := range grid.space {
-		if num > 1 {
-			output++
-		}
-	}
-
-	return
+	return countOverlaps(content, Horizontal|Vertical|Diagonal)
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	modeFlag := flag.String("mode", "raster", "raster, lattice or exact")
+	heatmapFlag := flag.String("heatmap", "", "save a .png or .pgm heatmap of Part Two")
+	overlayFlag := flag.Bool("overlay", false, "draw the lines over the heatmap")
+	grayFlag := flag.Bool("gray", false, "use a greyscale colour ramp for the heatmap")
+	anySlopeFlag := flag.Bool("any-slope", false, "also count lines at any slope (and draw them in the heatmap)")
+
+	flag.Parse()
+
+	switch *modeFlag {
+	case "raster":
+		overlapMode = Raster
+	case "lattice":
+		overlapMode = Lattice
+	case "exact":
+		overlapMode = Exact
+	default:
+		fmt.Println("unknown -mode", *modeFlag)
+		return
+	}
+
 	vals := utils.LoadAsLines(filename)
 
 	answer, err := PartOne(vals)
@@ -199,4 +187,52 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	include := Horizontal | Vertical | Diagonal
+
+	if *anySlopeFlag {
+		include = AnySlope
+
+		answer3, err := countOverlaps(vals, include)
+
+		if err != nil {
+			fmt.Println("failed to count any slope", err)
+			return
+		}
+
+		fmt.Printf("Any slope: %d \n", answer3)
+	}
+
+	if *heatmapFlag != "" {
+		opts := defaultHeatmap
+		opts.overlay = *overlayFlag
+
+		if *grayFlag {
+			opts.ramp = utils.GrayRamp
+		}
+
+		if err := saveHeatmap(vals, *heatmapFlag, include, opts); err != nil {
+			fmt.Println("failed to save heatmap", err)
+		}
+	}
+}
+
+func saveHeatmap(content []string, filename string, include LineKind, opts HeatmapOptions) error {
+	grid := grid{
+		include: include,
+	}
+
+	if err := grid.load(content); err != nil {
+		return err
+	}
+
+	grid.drawLines()
+
+	img, err := grid.heatmap(opts)
+
+	if err != nil {
+		return err
+	}
+
+	return utils.SaveImage(filename, img)
 }
@@ -56,3 +56,141 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestModesAgreeOnExample(t *testing.T) {
+	vals := fileLoader("example.txt")
+
+	defer func() {
+		overlapMode = Raster
+	}()
+
+	for _, mode := range []Mode{Lattice, Exact} {
+		overlapMode = mode
+
+		for part, fnc := range map[int]func([]string) (int, error){1: PartOne, 2: PartTwo} {
+			val, err := fnc(vals)
+
+			if err != nil {
+				t.Log("error should be nil", err)
+				t.Fail()
+			}
+
+			if val != answers[part] {
+				t.Logf("Mode %d part %d should be %d, but got %d", mode, part, answers[part], val)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestIntersectionSemantics(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lines                  []string
+		raster, lattice, exact int
+	}{
+		// an X between integer points
+		{"half crossing", []string{"0,0 -> 1,1", "0,1 -> 1,0"}, 0, 0, 1},
+		// shallow slopes crossing at (2,1); Bresenham rounds both through (1,1) too
+		{"oblique", []string{"0,0 -> 4,2", "0,2 -> 4,0"}, 2, 1, 1},
+		// three lines through one point count once
+		{"triple", []string{"0,2 -> 4,2", "2,0 -> 2,4", "0,0 -> 4,4"}, 1, 1, 1},
+		// collinear overlap (2,1) (4,2) (6,3), crossed inside the overlap;
+		// Bresenham also draws the cells in between
+		{"collinear", []string{"0,0 -> 6,3", "2,1 -> 8,4", "4,0 -> 4,4"}, 5, 3, 3},
+		{"point", []string{"3,3 -> 3,3", "0,3 -> 5,3"}, 1, 1, 1},
+		{"far away", []string{"999999999,0 -> 999999999,5", "999999990,2 -> 1000000009,2"}, 1, 1, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := grid{include: AnySlope}
+
+			if err := g.load(test.lines); err != nil {
+				t.Log("error should be nil", err)
+				t.FailNow()
+			}
+
+			g.drawLines()
+
+			got := [3]int{g.overlaps(), g.intersections(false), g.intersections(true)}
+			expected := [3]int{test.raster, test.lattice, test.exact}
+
+			if got != expected {
+				t.Logf("raster/lattice/exact should be %v, but got %v", expected, got)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestBresenham(t *testing.T) {
+	points := []coords{}
+	line{coords{0, 0}, coords{5, 2}}.rasterize(func(x, y int) {
+		points = append(points, coords{x, y})
+	})
+
+	expected := []coords{{0, 0}, {1, 0}, {2, 1}, {3, 1}, {4, 2}, {5, 2}}
+
+	if len(points) != len(expected) {
+		t.Logf("Answer should be %v, but got %v", expected, points)
+		t.FailNow()
+	}
+
+	for i := range points {
+		if points[i] != expected[i] {
+			t.Logf("Answer should be %v, but got %v", expected, points)
+			t.Fail()
+			break
+		}
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	g := grid{}
+	err := g.load([]string{"0,0 -> 2000000000,0"})
+
+	if err == nil {
+		t.Log("expected an error for a huge coordinate")
+		t.Fail()
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	g := grid{include: Horizontal | Vertical}
+	g.load(fileLoader("example.txt"))
+	g.drawLines()
+
+	opts := defaultHeatmap
+	img, err := g.heatmap(opts)
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	if img.Bounds().Dx() != 10 || img.Bounds().Dy() != 10 {
+		t.Logf("heatmap should be 10x10, but got %v", img.Bounds())
+		t.Fail()
+	}
+
+	// (3,4) is one of the overlaps, and the max is 2
+	if img.RGBAAt(3, 4) != opts.ramp.At(1) {
+		t.Logf("overlap should be the hottest colour, but got %v", img.RGBAAt(3, 4))
+		t.Fail()
+	}
+
+	if img.RGBAAt(0, 0) != opts.ramp.At(0) {
+		t.Logf("empty cell should be the coldest colour, but got %v", img.RGBAAt(0, 0))
+		t.Fail()
+	}
+
+	// diagonals aren't counted, but are still overlaid
+	opts.overlay = true
+	img, _ = g.heatmap(opts)
+
+	if img.RGBAAt(0, 0) == opts.ramp.At(0) {
+		t.Log("overlay should draw the 0,0 -> 8,8 diagonal")
+		t.Fail()
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// anything bigger is almost certainly a parsing mistake
+const maxHeatmapPixels = 1 << 24
+
+type HeatmapOptions struct {
+	// from no lines to the most overlapped cell
+	ramp utils.ColorRamp
+	// draws the original lines on top of the counts
+	overlay      bool
+	overlayColor color.RGBA
+}
+
+var defaultHeatmap = HeatmapOptions{
+	ramp:         utils.HeatRamp,
+	overlayColor: color.RGBA{0, 200, 255, 255},
+}
+
+// renders the overlap counts (from drawLines) over the grid's
+// bounding box, one pixel per cell
+func (g *grid) heatmap(opts HeatmapOptions) (*image.RGBA, error) {
+	if g.space == nil {
+		return nil, errors.New("heatmap needs drawLines first")
+	}
+
+	width := g.max[0] - g.min[0] + 1
+	height := g.max[1] - g.min[1] + 1
+
+	if width*height > maxHeatmapPixels {
+		return nil, errors.New("grid is too large for a heatmap")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	most := 0
+
+	for _, count := range g.space {
+		most = utils.MaxInt(most, count)
+	}
+
+	background := opts.ramp.At(0)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, background)
+		}
+	}
+
+	for cell, count := range g.space {
+		img.SetRGBA(cell[0]-g.min[0], cell[1]-g.min[1], opts.ramp.At(float64(count)/float64(most)))
+	}
+
+	if opts.overlay {
+		for _, l := range g.lines {
+			l.rasterize(func(x, y int) {
+				x, y = x-g.min[0], y-g.min[1]
+				// half see-through, so the counts still show
+				img.SetRGBA(x, y, utils.Blend(img.RGBAAt(x, y), opts.overlayColor, 0.5))
+			})
+		}
+	}
+
+	return img, nil
+}
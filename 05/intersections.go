@@ -0,0 +1,291 @@
+package main
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// identifies the infinite line a segment lies on
+type lineKey struct {
+	// smallest integer direction, pointing right (or up)
+	dir coords
+	// same for every point on the line: dir.x*y - dir.y*x
+	offset int
+}
+
+type segment struct {
+	line
+	key lineKey
+	// where the ends are along the line (dot product with dir);
+	// integer points are dir·dir apart
+	lo, hi int
+	// bounding box
+	min, max coords
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+func cross(a, b coords) int {
+	return a[0]*b[1] - a[1]*b[0]
+}
+
+func dot(a, b coords) int {
+	return a[0]*b[0] + a[1]*b[1]
+}
+
+func subtract(a, b coords) coords {
+	return coords{a[0] - b[0], a[1] - b[1]}
+}
+
+func newSegment(l line) *segment {
+	dx, dy := l.to[0]-l.from[0], l.to[1]-l.from[1]
+	g := gcd(utils.Abs(dx), utils.Abs(dy))
+
+	dir := coords{1, 0}
+
+	if g != 0 {
+		dir = coords{dx / g, dy / g}
+
+		if dir[0] < 0 || (dir[0] == 0 && dir[1] < 0) {
+			dir = coords{-dir[0], -dir[1]}
+		}
+	}
+
+	a, b := dot(dir, l.from), dot(dir, l.to)
+
+	return &segment{
+		line: l,
+		key: lineKey{
+			dir:    dir,
+			offset: cross(dir, l.from),
+		},
+		lo:  utils.MinInt(a, b),
+		hi:  utils.MaxInt(a, b),
+		min: coords{utils.MinInt(l.from[0], l.to[0]), utils.MinInt(l.from[1], l.to[1])},
+		max: coords{utils.MaxInt(l.from[0], l.to[0]), utils.MaxInt(l.from[1], l.to[1])},
+	}
+}
+
+// where two segments on the same line are covered at least twice,
+// as [from, to] positions along the line
+func collinearOverlaps(segments []*segment) (overlaps [][2]int) {
+	type event struct {
+		pos, delta int
+	}
+
+	events := make([]event, 0, len(segments)*2)
+
+	for _, seg := range segments {
+		events = append(events, event{seg.lo, 1}, event{seg.hi, -1})
+	}
+
+	// ends are inclusive, so starts go first
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].pos == events[j].pos {
+			return events[i].delta > events[j].delta
+		}
+		return events[i].pos < events[j].pos
+	})
+
+	depth := 0
+	start := 0
+
+	for _, e := range events {
+		depth += e.delta
+
+		if e.delta > 0 && depth == 2 {
+			start = e.pos
+		} else if e.delta < 0 && depth == 1 {
+			overlaps = append(overlaps, [2]int{start, e.pos})
+		}
+	}
+
+	return
+}
+
+// a point where segments from different lines cross
+type crossing struct {
+	x, y *big.Rat
+	// the lines that pass through it
+	keys map[lineKey]bool
+}
+
+func onSegment(point coords, seg *segment) bool {
+	return cross(subtract(seg.to, seg.from), subtract(point, seg.from)) == 0 &&
+		point[0] >= seg.min[0] && point[0] <= seg.max[0] &&
+		point[1] >= seg.min[1] && point[1] <= seg.max[1]
+}
+
+func ratPoint(point coords) (*big.Rat, *big.Rat) {
+	return big.NewRat(int64(point[0]), 1), big.NewRat(int64(point[1]), 1)
+}
+
+// where two segments on different lines cross, if they do
+func intersect(a, b *segment) (x, y *big.Rat, ok bool) {
+	p, q := a.from, b.from
+	r, s := subtract(a.to, p), subtract(b.to, q)
+
+	// either segment could be a single point
+	if r == (coords{}) {
+		if onSegment(p, b) {
+			x, y = ratPoint(p)
+			return x, y, true
+		}
+		return
+	}
+
+	if s == (coords{}) {
+		if onSegment(q, a) {
+			x, y = ratPoint(q)
+			return x, y, true
+		}
+		return
+	}
+
+	denom := cross(r, s)
+
+	// parallel
+	if denom == 0 {
+		return
+	}
+
+	qp := subtract(q, p)
+	t := cross(qp, s)
+	u := cross(qp, r)
+
+	if denom < 0 {
+		denom, t, u = -denom, -t, -u
+	}
+
+	if t < 0 || t > denom || u < 0 || u > denom {
+		return
+	}
+
+	// p + r * t/denom, which can overflow an int
+	bigDenom := big.NewInt(int64(denom))
+
+	at := func(start, step int) *big.Rat {
+		num := new(big.Int).Mul(big.NewInt(int64(start)), bigDenom)
+		num.Add(num, new(big.Int).Mul(big.NewInt(int64(step)), big.NewInt(int64(t))))
+
+		return new(big.Rat).SetFrac(num, bigDenom)
+	}
+
+	return at(p[0], r[0]), at(p[1], r[1]), true
+}
+
+// counts points covered by at least two lines, without drawing:
+// overlapping collinear segments are 1d interval problems, and
+// crossings come from checking every pair of segments whose
+// bounding boxes overlap (still O(n²) when they all do); `exact`
+// also counts crossings that aren't on integer coordinates
+func (g *grid) intersections(exact bool) (output int) {
+	segments := []*segment{}
+	byLine := map[lineKey][]*segment{}
+
+	for _, l := range g.lines {
+		if g.include&l.kind() == 0 {
+			continue
+		}
+
+		seg := newSegment(l)
+		segments = append(segments, seg)
+		byLine[seg.key] = append(byLine[seg.key], seg)
+	}
+
+	overlaps := map[lineKey][][2]int{}
+
+	for key, group := range byLine {
+		if len(group) < 2 {
+			continue
+		}
+
+		step := dot(key.dir, key.dir)
+
+		for _, overlap := range collinearOverlaps(group) {
+			overlaps[key] = append(overlaps[key], overlap)
+			output += (overlap[1]-overlap[0])/step + 1
+		}
+	}
+
+	// by left edge, so segments that end before this one starts can be
+	// dropped for good
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].min[0] < segments[j].min[0]
+	})
+
+	crossings := map[string]*crossing{}
+	active := []*segment{}
+
+	for _, seg := range segments {
+		stillActive := active[:0]
+
+		for _, other := range active {
+			if other.max[0] >= seg.min[0] {
+				stillActive = append(stillActive, other)
+			}
+		}
+
+		active = stillActive
+
+		for _, other := range active {
+			if other.key == seg.key || other.max[1] < seg.min[1] || other.min[1] > seg.max[1] {
+				continue
+			}
+
+			x, y, ok := intersect(seg, other)
+
+			if !ok {
+				continue
+			}
+
+			id := x.RatString() + "," + y.RatString()
+			point, ok := crossings[id]
+
+			if !ok {
+				point = &crossing{x, y, map[lineKey]bool{}}
+				crossings[id] = point
+			}
+
+			point.keys[seg.key] = true
+			point.keys[other.key] = true
+		}
+
+		active = append(active, seg)
+	}
+
+crossings:
+	for _, point := range crossings {
+		if !point.x.IsInt() || !point.y.IsInt() {
+			if exact {
+				output++
+			}
+			continue
+		}
+
+		at := coords{int(point.x.Num().Int64()), int(point.y.Num().Int64())}
+
+		// already counted as part of a collinear overlap
+		for key := range point.keys {
+			pos := dot(key.dir, at)
+
+			for _, overlap := range overlaps[key] {
+				if pos >= overlap[0] && pos <= overlap[1] {
+					continue crossings
+				}
+			}
+		}
+
+		output++
+	}
+
+	return
+}
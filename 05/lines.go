@@ -0,0 +1,77 @@
+package main
+
+import "github.com/bozdoz/advent-of-code-2021/utils"
+
+// LineKind is a bitmask, so a grid can include several kinds
+type LineKind int
+
+const (
+	Horizontal LineKind = 1 << iota
+	Vertical
+	// exactly 45 degrees
+	Diagonal
+	// any other slope
+	Oblique
+
+	AnySlope = Horizontal | Vertical | Diagonal | Oblique
+)
+
+// a single point counts as horizontal
+func (l line) kind() LineKind {
+	dx := utils.Abs(l.to[0] - l.from[0])
+	dy := utils.Abs(l.to[1] - l.from[1])
+
+	switch {
+	case dy == 0:
+		return Horizontal
+	case dx == 0:
+		return Vertical
+	case dx == dy:
+		return Diagonal
+	}
+
+	return Oblique
+}
+
+// Bresenham's line algorithm, for any slope in any direction;
+// horizontal, vertical and 45 degree lines hit every point exactly
+func (l line) rasterize(plot func(x, y int)) {
+	x, y := l.from[0], l.from[1]
+	x2, y2 := l.to[0], l.to[1]
+
+	dx := utils.Abs(x2 - x)
+	dy := -utils.Abs(y2 - y)
+
+	sx, sy := 1, 1
+
+	if x > x2 {
+		sx = -1
+	}
+
+	if y > y2 {
+		sy = -1
+	}
+
+	err := dx + dy
+
+	for {
+		// beginning coord should be counted
+		plot(x, y)
+
+		if x == x2 && y == y2 {
+			return
+		}
+
+		e2 := 2 * err
+
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 
@@ -11,67 +14,130 @@ import (
 // different puzzles require different file loaders
 var FileLoader = utils.LoadAsString
 
-// map of counter -> count
-type State [9]int
+// the puzzle's timers
+const (
+	defaultReset   = 6
+	defaultNewborn = 8
+)
+
+type School struct {
+	// timer a fish goes back to after spawning
+	reset int
+	// timer a newborn fish starts at
+	newborn int
+	// fish per timer value (index is the timer)
+	counts []*big.Int
+	// optional: all counts are mod this
+	modulus *big.Int
+}
+
+// constructor-like function to load a School, with any timers
+func newSchool(data string, reset, newborn int) (*School, error) {
+	if reset < 0 || newborn < 0 {
+		return nil, errors.New("timers can't be negative")
+	}
+
+	size := utils.MaxInt(reset, newborn) + 1
+	school := &School{
+		reset:   reset,
+		newborn: newborn,
+		counts:  make([]*big.Int, size),
+	}
+
+	for i := range school.counts {
+		school.counts[i] = new(big.Int)
+	}
 
-func loadState(data string) (state State, err error) {
 	// get first line
 	lines := strings.Split(data, "\n")
 	vals := strings.Split(lines[0], ",")
 
 	for _, val := range vals {
-		i, err := strconv.Atoi(val)
+		i, err := strconv.Atoi(strings.TrimSpace(val))
 
 		if err != nil {
-			return state, err
+			return nil, err
 		}
 
-		state[i]++
+		if i < 0 || i >= size {
+			return nil, fmt.Errorf("timer %d is out of range 0-%d", i, size-1)
+		}
+
+		school.counts[i].Add(school.counts[i], big.NewInt(1))
 	}
 
-	return
+	return school, nil
 }
 
-// incrementDay decrements fish counters
-func incrementDay(state *State) {
-	populating := state[0]
+// one day as a matrix: every timer moves down one,
+// and 0-day fish go to both reset and newborn
+func (school *School) transition() utils.Matrix {
+	size := len(school.counts)
+	matrix := utils.NewMatrix(size)
 
-	// moves all counters to previous index
-	for i, val := range state[1:] {
-		state[i] = val
+	for i := 0; i < size-1; i++ {
+		matrix[i][i+1].SetInt64(1)
 	}
 
-	// 0-day counters are both moved to 8 and copied to 6
-	state[8] = populating
-	state[6] += populating
+	matrix[school.reset][0].Add(matrix[school.reset][0], big.NewInt(1))
+	matrix[school.newborn][0].Add(matrix[school.newborn][0], big.NewInt(1))
+
+	return matrix
 }
 
-func fastforward(state *State, days int) {
-	for i := 0; i < days; i++ {
-		incrementDay(state)
-	}
+// fish per timer after any number of days, without simulating each one
+func (school *School) population(days uint64) []*big.Int {
+	step := school.transition().Power(days, school.modulus)
+
+	return step.Apply(school.counts, school.modulus)
 }
 
-func PartOne(content string) (output int, err error) {
-	state, err := loadState(content)
+// all fish after any number of days
+func (school *School) total(days uint64) *big.Int {
+	total := new(big.Int)
 
-	fastforward(&state, 80)
+	for _, count := range school.population(days) {
+		total.Add(total, count)
+	}
 
-	return utils.Sum(state[:]...), err
+	return utils.Reduce(total, school.modulus)
 }
 
-func PartTwo(content string) (output int, err error) {
-	state, err := loadState(content)
+func countFish(content string, days uint64) (int, error) {
+	school, err := newSchool(content, defaultReset, defaultNewborn)
+
+	if err != nil {
+		return -1, err
+	}
+
+	total := school.total(days)
+
+	if !total.IsInt64() {
+		return -1, errors.New("too many fish for an int")
+	}
 
-	fastforward(&state, 256)
+	return int(total.Int64()), nil
+}
+
+func PartOne(content string) (output int, err error) {
+	return countFish(content, 80)
+}
 
-	return utils.Sum(state[:]...), err
+func PartTwo(content string) (output int, err error) {
+	return countFish(content, 256)
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	daysFlag := flag.Uint64("days", 0, "also print the population after this many days")
+	modFlag := flag.String("mod", "", "print the population mod this number")
+	resetFlag := flag.Int("reset", defaultReset, "timer after spawning")
+	newbornFlag := flag.Int("newborn", defaultNewborn, "timer of new fish")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -91,4 +157,32 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *daysFlag == 0 {
+		return
+	}
+
+	school, err := newSchool(data, *resetFlag, *newbornFlag)
+
+	if err != nil {
+		fmt.Println("failed to load school", err)
+		return
+	}
+
+	if *modFlag != "" {
+		modulus, ok := new(big.Int).SetString(*modFlag, 10)
+
+		if !ok || modulus.Sign() <= 0 {
+			fmt.Println("invalid -mod", *modFlag)
+			return
+		}
+
+		school.modulus = modulus
+	}
+
+	for timer, count := range school.population(*daysFlag) {
+		fmt.Printf("Timer %d: %s \n", timer, count)
+	}
+
+	fmt.Printf("Total: %s \n", school.total(*daysFlag))
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"math/big"
 	"testing"
 )
 
@@ -51,3 +52,67 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestPopulationBreakdown(t *testing.T) {
+	school, _ := newSchool(FileLoader("example.txt"), defaultReset, defaultNewborn)
+
+	// after 18 days: 6,0,6,4,5,6,0,1,1,2,6,0,1,1,1,2,2,3,3,4,6,7,8,8,8,8
+	expected := []int64{3, 5, 3, 2, 2, 1, 5, 1, 4}
+
+	for timer, count := range school.population(18) {
+		if count.Int64() != expected[timer] {
+			t.Logf("Timer %d should have %d fish, but got %s", timer, expected[timer], count)
+			t.Fail()
+		}
+	}
+}
+
+func TestHugeDays(t *testing.T) {
+	school, _ := newSchool(FileLoader("example.txt"), defaultReset, defaultNewborn)
+
+	total := school.total(1000)
+
+	if total.IsInt64() {
+		t.Log("1000 days should overflow an int64")
+		t.Fail()
+	}
+
+	// same answer mod a prime, with and without the modulus
+	modulus := big.NewInt(1_000_000_007)
+	expected := new(big.Int).Mod(total, modulus)
+
+	school.modulus = modulus
+
+	if val := school.total(1000); val.Cmp(expected) != 0 {
+		t.Logf("Answer should be %s, but got %s", expected, val)
+		t.Fail()
+	}
+
+	// 10^12 days is only ~40 squarings
+	if val := school.total(1_000_000_000_000); val.Cmp(modulus) >= 0 {
+		t.Logf("Answer should be reduced mod %s, but got %s", modulus, val)
+		t.Fail()
+	}
+}
+
+func TestCustomTimers(t *testing.T) {
+	// every fish spawns every day, and newborns spawn right away
+	school, err := newSchool("0,0", 0, 0)
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	if val := school.total(10); val.Int64() != 2<<10 {
+		t.Logf("Answer should be %d, but got %s", 2<<10, val)
+		t.Fail()
+	}
+
+	_, err = newSchool("9", defaultReset, defaultNewborn)
+
+	if err == nil {
+		t.Log("expected an error for an out of range timer")
+		t.Fail()
+	}
+}
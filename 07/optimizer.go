@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// fuel to move a single crab some distance; must be convex
+type CostFunc func(dist int) int
+
+// each step costs 1
+func linearCost(dist int) int {
+	return dist
+}
+
+// each step costs 1 more than the last: 1 + 2 + ... + dist
+func triangularCost(dist int) int {
+	return dist * (dist + 1) / 2
+}
+
+type CostModel struct {
+	cost CostFunc
+	// optional shortcut: narrows down where the optimum is
+	bounds func(positions []int) (lo, hi int)
+}
+
+var (
+	// the median minimizes the sum of absolute distances
+	// (any position between the two middle values)
+	Linear = CostModel{
+		cost: linearCost,
+		bounds: func(positions []int) (lo, hi int) {
+			n := len(positions)
+
+			return utils.Select(positions, (n-1)/2), utils.Select(positions, n/2)
+		},
+	}
+	// the mean minimizes the sum of squared distances, and the
+	// triangular optimum is within 1/2 of that
+	Triangular = CostModel{
+		cost: triangularCost,
+		bounds: func(positions []int) (lo, hi int) {
+			mean := utils.Mean(positions)
+
+			return int(math.Floor(mean - 0.5)), int(math.Ceil(mean + 0.5))
+		},
+	}
+)
+
+// finds a minimum of a convex function over [lo, hi]
+type Search func(fuel func(pos int) int, lo, hi int) int
+
+// integer ternary search
+func ternarySearch(fuel func(pos int) int, lo, hi int) int {
+	for hi-lo > 2 {
+		third := (hi - lo) / 3
+		m1, m2 := lo+third, hi-third
+
+		f1, f2 := fuel(m1), fuel(m2)
+
+		if f1 < f2 {
+			hi = m2 - 1
+		} else if f1 > f2 {
+			lo = m1 + 1
+		} else {
+			// convex: a minimum is between (or on) two equal points
+			lo, hi = m1, m2
+		}
+	}
+
+	return bruteForce(fuel, lo, hi)
+}
+
+var invPhi = (math.Sqrt(5) - 1) / 2
+
+// golden-section search: like ternary, but the probe that survives
+// each round is reused, so there's only one new fuel call per round
+func goldenSearch(fuel func(pos int) int, lo, hi int) int {
+	probe := func(lo, hi int) (m1, m2 int) {
+		offset := int(math.Round(float64(hi-lo) * invPhi))
+
+		return hi - offset, lo + offset
+	}
+
+	m1, m2 := probe(lo, hi)
+	f1, f2 := 0, 0
+
+	if m1 < m2 {
+		f1, f2 = fuel(m1), fuel(m2)
+	}
+
+	// rounding can make the probes meet once the range is small
+	for hi-lo > 2 && m1 < m2 {
+		// convex: a minimum is left of m2 (or between two equal points)
+		if f1 <= f2 {
+			hi = m2
+			m2, f2 = m1, f1
+			m1, _ = probe(lo, hi)
+
+			if m1 < m2 {
+				f1 = fuel(m1)
+			}
+		} else {
+			lo = m1
+			m1, f1 = m2, f2
+			_, m2 = probe(lo, hi)
+
+			if m1 < m2 {
+				f2 = fuel(m2)
+			}
+		}
+	}
+
+	return bruteForce(fuel, lo, hi)
+}
+
+// lowest position with the lowest fuel
+func bruteForce(fuel func(pos int) int, lo, hi int) int {
+	best, bestFuel := lo, fuel(lo)
+
+	for pos := lo + 1; pos <= hi; pos++ {
+		if val := fuel(pos); val < bestFuel {
+			best, bestFuel = pos, val
+		}
+	}
+
+	return best
+}
+
+type Result struct {
+	// every position from `from` to `to` has the lowest fuel
+	from, to int
+	fuel     int
+}
+
+// custom string representation
+func (result Result) String() string {
+	if result.from == result.to {
+		return fmt.Sprintf("%d fuel at position %d", result.fuel, result.from)
+	}
+
+	return fmt.Sprintf("%d fuel at positions %d to %d", result.fuel, result.from, result.to)
+}
+
+func totalFuel(positions []int, cost CostFunc, ref int) (fuel int) {
+	for _, val := range positions {
+		fuel += cost(utils.Abs(val - ref))
+	}
+
+	return
+}
+
+// finds the cheapest position(s) to align every crab
+func optimize(positions []int, model CostModel, search Search) Result {
+	memo := map[int]int{}
+
+	fuel := func(pos int) int {
+		val, ok := memo[pos]
+
+		if !ok {
+			val = totalFuel(positions, model.cost, pos)
+			memo[pos] = val
+		}
+
+		return val
+	}
+
+	lo, hi := utils.MinInt(positions...), utils.MaxInt(positions...)
+
+	if model.bounds != nil {
+		lo, hi = model.bounds(positions)
+	}
+
+	best := search(fuel, lo, hi)
+	min := fuel(best)
+
+	// a convex function's minimum is one flat stretch:
+	// binary search for where it starts and ends
+	left, right := lo, best
+
+	for left < right {
+		mid := left + (right-left)/2
+
+		if fuel(mid) == min {
+			right = mid
+		} else {
+			left = mid + 1
+		}
+	}
+
+	from := left
+
+	left, right = best, hi
+
+	for left < right {
+		mid := left + (right-left+1)/2
+
+		if fuel(mid) == min {
+			left = mid
+		} else {
+			right = mid - 1
+		}
+	}
+
+	return Result{from, left, min}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"sort"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
 )
@@ -10,103 +9,12 @@ import (
 // different puzzles require different file loaders
 var FileLoader = utils.LoadCSVInt
 
-// maybe utility (math.Abs is float64)
-func AbsDiff(a int, b int) int {
-	diff := a - b
-
-	if diff < 0 {
-		diff *= -1
-	}
-
-	return diff
-}
-
-func calcLinearFuel(positions []int, ref int) (fuel int) {
-	for _, val := range positions {
-		fuel += AbsDiff(val, ref)
-	}
-
-	return
-}
-
-// TODO: pointers to slices
-// TODO: slices should have explicit capacities
-// maybe utility function
-func sortedUnique(data []int) (unique []int) {
-	set := map[int]bool{}
-
-	for _, val := range data {
-		set[val] = true
-	}
-
-	for key := range set {
-		unique = append(unique, key)
-	}
-
-	sort.Ints(unique)
-
-	return
-}
-
 func PartOne(content []int) (minfuel int, err error) {
-	unique := sortedUnique(content)
-
-	minfuel = calcLinearFuel(content, unique[0])
-
-	// checks positions in dataset only (naive)
-	for _, val := range unique[1:] {
-		newfuel := calcLinearFuel(content, val)
-
-		if newfuel < minfuel {
-			minfuel = newfuel
-		} else {
-			break
-		}
-	}
-
-	return
-}
-
-// adds all iterations of numbers by decrementing by 1:
-// example: 4 = 4 + 3 + 2 + 1
-// update: Should have been dist * (dist + 1) / 2
-func getCumulativeFuel(dist int) (fuel int) {
-	for ; dist > 0; dist-- {
-		fuel += dist
-	}
-
-	return
-}
-
-// each change of 1 step in horizontal position costs 1 more unit of fuel than the last
-func calcExponentialFuel(positions []int, ref int) (fuel int) {
-	for _, val := range positions {
-		fuel += getCumulativeFuel(AbsDiff(val, ref))
-	}
-
-	return
+	return optimize(content, Linear, ternarySearch).fuel, nil
 }
 
 func PartTwo(content []int) (minfuel int, err error) {
-	unique := sortedUnique(content)
-
-	min := unique[0]
-	max := unique[len(unique)-1]
-
-	minfuel = calcExponentialFuel(content, min)
-
-	// checks positions not in dataset
-	for val := min + 1; val < max; val++ {
-		newfuel := calcExponentialFuel(content, val)
-
-		if newfuel < minfuel {
-			minfuel = newfuel
-		} else {
-			break
-		}
-	}
-
-	return
+	return optimize(content, Triangular, ternarySearch).fuel, nil
 }
 
 func main() {
@@ -115,21 +23,15 @@ func main() {
 
 	data := FileLoader(filename)
 
-	answer, err := PartOne(data)
-
-	if err != nil {
-		fmt.Println("failed to parse PartOne", err)
-		return
-	}
-
-	fmt.Printf("Part One: %d \n", answer)
+	// the same as PartOne and PartTwo, keeping the whole Result to print
+	// where the crabs line up
+	result := optimize(data, Linear, ternarySearch)
 
-	answer2, err := PartTwo(data)
+	fmt.Printf("Part One: %d \n", result.fuel)
+	fmt.Println(result)
 
-	if err != nil {
-		fmt.Println("failed to parse PartTwo", err)
-		return
-	}
+	result2 := optimize(data, Triangular, ternarySearch)
 
-	fmt.Printf("Part Two: %d \n", answer2)
+	fmt.Printf("Part Two: %d \n", result2.fuel)
+	fmt.Println(result2)
 }
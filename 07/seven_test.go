@@ -2,6 +2,8 @@ package main
 
 import (
 	"testing"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
 )
 
 // fill in the answers for each part (as they come)
@@ -51,3 +53,86 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestOptimizer(t *testing.T) {
+	vals := FileLoader("example.txt")
+
+	// squared distance has no shortcut, so it searches every position
+	squared := CostModel{
+		cost: func(dist int) int {
+			return dist * dist
+		},
+	}
+
+	tests := []struct {
+		name     string
+		model    CostModel
+		expected Result
+	}{
+		{"linear", Linear, Result{2, 2, 37}},
+		{"triangular", Triangular, Result{5, 5, 168}},
+		{"squared", squared, Result{5, 5, 291}},
+	}
+
+	for _, test := range tests {
+		for name, search := range map[string]Search{"ternary": ternarySearch, "golden": goldenSearch} {
+			result := optimize(vals, test.model, search)
+
+			if result != test.expected {
+				t.Logf("%s %s should be %v, but got %v", test.name, name, test.expected, result)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestOptimalRange(t *testing.T) {
+	// anywhere from 3 to 10 costs the same
+	vals := []int{0, 3, 10, 20}
+	expected := Result{3, 10, 27}
+
+	for _, model := range []CostModel{Linear, {cost: linearCost}} {
+		result := optimize(vals, model, goldenSearch)
+
+		if result != expected {
+			t.Logf("Answer should be %v, but got %v", expected, result)
+			t.Fail()
+		}
+	}
+}
+
+func TestGoldenSearchCalls(t *testing.T) {
+	calls := 0
+	fuel := func(pos int) int {
+		calls++
+
+		return utils.Abs(pos - 123456)
+	}
+
+	best := goldenSearch(fuel, 0, 1_000_000)
+
+	if best != 123456 {
+		t.Logf("Answer should be %d, but got %d", 123456, best)
+		t.Fail()
+	}
+
+	// about log(1e6)/log(phi) ≈ 29 rounds, with one new call each
+	if calls > 40 {
+		t.Logf("expected at most 40 fuel calls, got %d", calls)
+		t.Fail()
+	}
+
+	// every position and range size still finds the minimum
+	for hi := 0; hi < 40; hi++ {
+		for min := 0; min <= hi; min++ {
+			got := goldenSearch(func(pos int) int {
+				return utils.Abs(pos - min)
+			}, 0, hi)
+
+			if got != min {
+				t.Logf("0..%d: expected %d, got %d", hi, min, got)
+				t.Fail()
+			}
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+var (
+	ErrImpossible = errors.New("no wiring matches the patterns")
+	ErrAmbiguous  = errors.New("more than one wiring matches the patterns")
+)
+
+// the puzzle's segments:
+//
+//	 aaaa
+//	b    c
+//	b    c
+//	 dddd
+//	e    f
+//	e    f
+//	 gggg
+const sevenSegments = "abcdefg"
+
+var decimalDigits = map[string]string{
+	"0": "abcefg",
+	"1": "cf",
+	"2": "acdeg",
+	"3": "acdfg",
+	"4": "bcdf",
+	"5": "abdfg",
+	"6": "abdefg",
+	"7": "acf",
+	"8": "abcdefg",
+	"9": "abcdfg",
+}
+
+// decimal digits, plus A b C d E F
+func hexDigits() map[string]string {
+	digits := map[string]string{
+		"A": "abcdef",
+		"b": "bdefg",
+		"C": "abeg",
+		"d": "cdefg",
+		"E": "abdeg",
+		"F": "abde",
+	}
+
+	for symbol, segments := range decimalDigits {
+		digits[symbol] = segments
+	}
+
+	return digits
+}
+
+// Display is any set of segments (up to 32), and which of them
+// light up for each symbol
+type Display struct {
+	segments string
+	// segment set -> symbol
+	symbols map[uint32]string
+	// every symbol's segment set, by how many segments it has
+	byLength map[int][]uint32
+}
+
+// constructor-like function to create a Display from a table
+// of symbol -> lit segments
+func newDisplay(segments string, table map[string]string) (*Display, error) {
+	if len(segments) > 32 {
+		return nil, errors.New("a display can have at most 32 segments")
+	}
+
+	display := &Display{
+		segments: segments,
+		symbols:  map[uint32]string{},
+		byLength: map[int][]uint32{},
+	}
+
+	for symbol, lit := range table {
+		mask, err := display.mask(lit)
+
+		if err != nil {
+			return nil, fmt.Errorf("symbol %q: %w", symbol, err)
+		}
+
+		if other, ok := display.symbols[mask]; ok {
+			return nil, fmt.Errorf("symbols %q and %q have the same segments", symbol, other)
+		}
+
+		display.symbols[mask] = symbol
+		display.byLength[len(lit)] = append(display.byLength[len(lit)], mask)
+	}
+
+	return display, nil
+}
+
+// a set of segments (or wires) as a bitmask
+func (display *Display) mask(pattern string) (mask uint32, err error) {
+	for _, char := range pattern {
+		i := -1
+
+		for j, segment := range display.segments {
+			if segment == char {
+				i = j
+				break
+			}
+		}
+
+		if i < 0 {
+			return 0, fmt.Errorf("unknown segment %q", char)
+		}
+
+		if mask&(1<<i) != 0 {
+			return 0, fmt.Errorf("segment %q is repeated", char)
+		}
+
+		mask |= 1 << i
+	}
+
+	return
+}
+
+// most wire assignments to try before calling an entry ambiguous
+const maxSteps = 1 << 20
+
+type solver struct {
+	display  *Display
+	patterns []uint32
+	outputs  []uint32
+	// wire -> segment, -1 until assigned
+	mapping  []int
+	assigned uint32
+	used     uint32
+	// output pattern -> the symbol it's been fixed to
+	fixed map[uint32]uint32
+	// symbols picked for the outputs so far
+	current []string
+	// first decoding found, and whether another disagreed
+	decoded   []string
+	ambiguous bool
+	// wire assignments tried, and whether that hit maxSteps
+	steps  int
+	gaveUp bool
+}
+
+// maps a set of wires onto segments (only assigned wires)
+func (s *solver) translate(wires uint32) (segments uint32) {
+	for wires != 0 {
+		wire := bits.TrailingZeros32(wires)
+		segments |= 1 << s.mapping[wire]
+		wires &= wires - 1
+	}
+
+	return
+}
+
+// can every pattern still become a symbol, given the wires assigned so far?
+func (s *solver) consistent() bool {
+	all := uint32(1)<<len(s.mapping) - 1
+
+	for _, pattern := range s.patterns {
+		in := s.translate(pattern & s.assigned)
+		out := s.translate(^pattern & s.assigned & all)
+		candidates := s.display.byLength[bits.OnesCount32(pattern)]
+
+		if symbol, ok := s.fixed[pattern]; ok {
+			candidates = []uint32{symbol}
+		}
+
+		ok := false
+
+		for _, symbol := range candidates {
+			if in&^symbol == 0 && out&symbol == 0 {
+				ok = true
+				break
+			}
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// whether the wires assigned so far can be finished off into a
+// complete wiring; it stops at the first one, since any will do
+func (s *solver) completes() bool {
+	wire := -1
+
+	for w := range s.mapping {
+		if s.assigned&(1<<w) == 0 {
+			wire = w
+			break
+		}
+	}
+
+	if wire == -1 {
+		return true
+	}
+
+	for segment := range s.mapping {
+		if s.used&(1<<segment) != 0 {
+			continue
+		}
+
+		if s.steps++; s.steps > maxSteps {
+			s.gaveUp = true
+			return false
+		}
+
+		s.mapping[wire] = segment
+		s.assigned |= 1 << wire
+		s.used |= 1 << segment
+
+		found := s.consistent() && s.completes()
+
+		s.mapping[wire] = -1
+		s.assigned &^= 1 << wire
+		s.used &^= 1 << segment
+
+		if found || s.gaveUp {
+			return found
+		}
+	}
+
+	return false
+}
+
+// output by output, tries each symbol it could be; a symbol counts if
+// some complete wiring agrees with it (and the symbols before it), so
+// this never enumerates wirings, only (at most two) decodings
+func (s *solver) search(i int) {
+	if i == len(s.outputs) {
+		s.record()
+		return
+	}
+
+	output := s.outputs[i]
+
+	// the same pattern twice is the same symbol twice
+	if symbol, ok := s.fixed[output]; ok {
+		s.current[i] = s.display.symbols[symbol]
+		s.search(i + 1)
+
+		return
+	}
+
+	for _, symbol := range s.display.byLength[bits.OnesCount32(output)] {
+		s.fixed[output] = symbol
+
+		if s.consistent() && s.completes() {
+			s.current[i] = s.display.symbols[symbol]
+			s.search(i + 1)
+		}
+
+		delete(s.fixed, output)
+
+		if s.ambiguous || s.gaveUp {
+			return
+		}
+	}
+}
+
+// a decoding that some wiring agrees with
+func (s *solver) record() {
+	if s.decoded == nil {
+		s.decoded = append([]string{}, s.current...)
+		return
+	}
+
+	// every decoding is different, so a second one is ambiguous
+	s.ambiguous = true
+}
+
+// works out the wiring from an entry's patterns, then decodes its
+// output; several wirings are fine as long as they agree on the output
+func (display *Display) decode(entry Entry) ([]string, error) {
+	s := &solver{
+		display: display,
+		mapping: make([]int, len(display.segments)),
+		fixed:   map[uint32]uint32{},
+	}
+
+	for i := range s.mapping {
+		s.mapping[i] = -1
+	}
+
+	for _, pattern := range entry.patterns {
+		mask, err := display.mask(pattern)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.patterns = append(s.patterns, mask)
+	}
+
+	for _, output := range entry.outputs {
+		mask, err := display.mask(output)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// outputs are symbols too, so they constrain the wiring
+		s.patterns = append(s.patterns, mask)
+		s.outputs = append(s.outputs, mask)
+	}
+
+	// short patterns (like 1 and 7) narrow things down the most
+	sort.Slice(s.patterns, func(i, j int) bool {
+		return bits.OnesCount32(s.patterns[i]) < bits.OnesCount32(s.patterns[j])
+	})
+
+	s.current = make([]string, len(s.outputs))
+	s.search(0)
+
+	if s.gaveUp {
+		return nil, fmt.Errorf("%w (gave up after %d steps)", ErrAmbiguous, maxSteps)
+	}
+
+	if s.ambiguous {
+		return nil, ErrAmbiguous
+	}
+
+	if s.decoded == nil {
+		return nil, ErrImpossible
+	}
+
+	return s.decoded, nil
+}
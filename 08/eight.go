@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -26,162 +27,64 @@ func PartOne(lines []string) (output int, err error) {
 	return counts[2] + counts[4] + counts[3] + counts[7], nil
 }
 
-const (
-	unknown = -1
-)
-
-type Pattern string
-
 type Entry struct {
-	patterns map[Pattern]int
-	digits   [4]Pattern
+	patterns []string
+	outputs  []string
 }
 
-func newPattern(p string) Pattern {
-	p = utils.SortString(p)
-
-	return Pattern(p)
-}
-
-func newEntry(line string) (entry Entry) {
+func newEntry(line string) (entry Entry, err error) {
 	parts := strings.Split(line, " | ")
 
-	// why do I always have to initialize this "nil map"
-	entry.patterns = map[Pattern]int{}
-
-	for _, pattern := range strings.Fields(parts[0]) {
-		entry.patterns[newPattern(pattern)] = unknown
+	if len(parts) != 2 {
+		return entry, errors.New("expected patterns | outputs")
 	}
 
-	for i, code := range strings.Fields(parts[1]) {
-		entry.digits[i] = newPattern(code)
-	}
+	entry.patterns = strings.Fields(parts[0])
+	entry.outputs = strings.Fields(parts[1])
 
 	return
 }
 
-// all parts of b are inside of a
-func (a *Pattern) contains(b Pattern) bool {
-outer:
-	for _, x := range b {
-		for _, y := range *a {
-			if x == y {
-				continue outer
-			}
+// decodes every entry on a display, and concatenates each output
+// into a number in the given base
+func sumOutputs(lines []string, display *Display, base int) (output int, err error) {
+	for i, line := range lines {
+		if line == "" {
+			continue
 		}
-		return false
-	}
 
-	return true
-}
-
-// if any candidates contains all of pattern, then assign it the value
-func (entry *Entry) deriveThroughIntersection(candidates []Pattern, pattern Pattern, value int) Pattern {
-	for _, candidate := range candidates {
-		if candidate.contains(pattern) {
-			entry.patterns[candidate] = value
+		entry, err := newEntry(line)
 
-			return candidate
+		if err != nil {
+			return -1, fmt.Errorf("line %d: %w", i+1, err)
 		}
-	}
-	panic(fmt.Sprintf("pattern not found: %s", pattern))
-}
-
-// created purely to make the remove method
-type PatternArr []Pattern
 
-func (arr PatternArr) remove(pattern Pattern) (out PatternArr) {
-	for _, val := range arr {
-		if val != pattern {
-			out = append(out, val)
-		}
-	}
+		symbols, err := display.decode(entry)
 
-	return
-}
-
-func (entry *Entry) derivePatterns() {
-	// save one and four because they are used to get other values
-	var one, four Pattern
-	var lenfive, lensix PatternArr
-
-	// givens: 1, 4, 7, 8
-	for pattern := range entry.patterns {
-		val := unknown
-		switch len(pattern) {
-		case 2:
-			val = 1
-			one = pattern
-		case 3:
-			val = 7
-		case 4:
-			val = 4
-			four = pattern
-		case 5:
-			// 2, 3, 5
-			lenfive = append(lenfive, pattern)
-		case 6:
-			// 0, 6, 9
-			lensix = append(lensix, pattern)
-		case 7:
-			val = 8
+		if err != nil {
+			return -1, fmt.Errorf("line %d: %w", i+1, err)
 		}
 
-		entry.patterns[pattern] = val
-	}
+		val, err := strconv.ParseInt(strings.Join(symbols, ""), base, 64)
 
-	// 2, 3 & 5 are all of length 5, but only 3 includes all segments from 1
-	matched := entry.deriveThroughIntersection(lenfive, one, 3)
-	lenfive = lenfive.remove(matched)
-
-	// 9 contains 4
-	matched = entry.deriveThroughIntersection(lensix, four, 9)
-	lensix = lensix.remove(matched)
-
-	// 0 contains 1
-	matched = entry.deriveThroughIntersection(lensix, one, 0)
-
-	// 6 remains
-	six := lensix.remove(matched)[0]
-	entry.patterns[six] = 6
-
-	// 2 & 5 are left: 6 contains 5
-	for _, candidate := range lenfive {
-		// reversed from above
-		if six.contains(candidate) {
-			entry.patterns[candidate] = 5
-		} else {
-			// only 2 remains
-			entry.patterns[candidate] = 2
+		if err != nil {
+			return -1, fmt.Errorf("line %d: %w", i+1, err)
 		}
-	}
-}
 
-// we concatenate each entry
-func (entry *Entry) concatDigits() (int, error) {
-	sum := ""
-	for _, pattern := range entry.digits {
-		sum += strconv.Itoa(entry.patterns[pattern])
+		output += int(val)
 	}
 
-	return strconv.Atoi(sum)
+	return
 }
 
 func PartTwo(lines []string) (output int, err error) {
-	for _, line := range lines {
-		entry := newEntry(line)
-		entry.derivePatterns()
-
-		sum, err := entry.concatDigits()
-
-		if err != nil {
-			return output, err
-		}
+	display, err := newDisplay(sevenSegments, decimalDigits)
 
-		output += sum
+	if err != nil {
+		return -1, err
 	}
 
-	return
+	return sumOutputs(lines, display, 10)
 }
 
 func main() {
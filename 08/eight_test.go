@@ -1,6 +1,9 @@
 package main
 
 import (
+	"errors"
+	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -51,3 +54,164 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// rewires every symbol in a table through a wiring of segment -> wire
+func scramble(segments string, table map[string]string, wiring string, symbols []string) (out []string) {
+	for _, symbol := range symbols {
+		wires := ""
+
+		for _, segment := range table[symbol] {
+			wires += string(wiring[strings.IndexRune(segments, segment)])
+		}
+
+		out = append(out, wires)
+	}
+
+	return
+}
+
+func TestHexDisplay(t *testing.T) {
+	table := hexDigits()
+	display, err := newDisplay(sevenSegments, table)
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	all := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "A", "b", "C", "d", "E", "F"}
+	outputs := []string{"C", "A", "F", "E"}
+
+	entry := Entry{
+		patterns: scramble(sevenSegments, table, "gfedcba", all),
+		outputs:  scramble(sevenSegments, table, "gfedcba", outputs),
+	}
+
+	symbols, err := display.decode(entry)
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	if strings.Join(symbols, "") != "CAFE" {
+		t.Logf("Answer should be CAFE, but got %v", symbols)
+		t.Fail()
+	}
+}
+
+func TestFourteenSegments(t *testing.T) {
+	segments := "abcdefghijklmn"
+	random := rand.New(rand.NewSource(14))
+	table := map[string]string{}
+	symbols := []string{}
+
+	// 30 made-up symbols with random segments
+	for len(table) < 30 {
+		lit := ""
+
+		for _, segment := range segments {
+			if random.Intn(2) == 0 {
+				lit += string(segment)
+			}
+		}
+
+		symbol := string(rune('A' + len(table)))
+		table[symbol] = lit
+		symbols = append(symbols, symbol)
+	}
+
+	display, err := newDisplay(segments, table)
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	wiring := "kdmabjnlcfihge"
+	entry := Entry{
+		patterns: scramble(segments, table, wiring, symbols),
+		outputs:  scramble(segments, table, wiring, []string{"H", "I"}),
+	}
+
+	decoded, err := display.decode(entry)
+
+	if err != nil || strings.Join(decoded, "") != "HI" {
+		t.Logf("Answer should be HI, but got %v (%v)", decoded, err)
+		t.Fail()
+	}
+}
+
+func TestFourteenSegmentsFewPatterns(t *testing.T) {
+	segments := "abcdefghijklmn"
+	table := map[string]string{
+		"8": segments,
+		"1": "bc",
+		"7": "abc",
+		"L": "def",
+	}
+
+	display, err := newDisplay(segments, table)
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	// every one of the 14! wirings agrees that this is an 8
+	entry := Entry{outputs: []string{"nmlkjihgfedcba"}}
+	decoded, err := display.decode(entry)
+
+	if err != nil || strings.Join(decoded, "") != "8" {
+		t.Logf("Answer should be 8, but got %v (%v)", decoded, err)
+		t.Fail()
+	}
+
+	// "7" and "L" both have 3 segments, and nothing tells them apart
+	entry = Entry{outputs: []string{"nmlkjihgfedcba", "abc"}}
+	_, err = display.decode(entry)
+
+	if !errors.Is(err, ErrAmbiguous) {
+		t.Logf("expected ErrAmbiguous, got %v", err)
+		t.Fail()
+	}
+
+	// the 1 is inside the 7, but not the L
+	entry, _ = newEntry("ab | abc")
+	decoded, err = display.decode(entry)
+
+	if err != nil || strings.Join(decoded, "") != "7" {
+		t.Logf("Answer should be 7, but got %v (%v)", decoded, err)
+		t.Fail()
+	}
+}
+
+func TestUnsolvable(t *testing.T) {
+	display, _ := newDisplay(sevenSegments, decimalDigits)
+
+	tests := map[string]error{
+		// a 1, and a five-segment digit with only half of it: 2 or 5
+		"ab | acdef": ErrAmbiguous,
+		// two different 1s
+		"ab cd | ab": ErrImpossible,
+		// a 1 and a 7 that doesn't contain it
+		"ab cde | ab": ErrImpossible,
+	}
+
+	for line, expected := range tests {
+		entry, _ := newEntry(line)
+		_, err := display.decode(entry)
+
+		if !errors.Is(err, expected) {
+			t.Logf("%q should fail with %v, but got %v", line, expected, err)
+			t.Fail()
+		}
+	}
+
+	_, err := PartTwo([]string{"ab | xy"})
+
+	if err == nil {
+		t.Log("expected an error for unknown segments")
+		t.Fail()
+	}
+}
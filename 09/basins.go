@@ -0,0 +1,249 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// walls in the puzzle are 9s
+const defaultWall = 9
+
+type BasinOptions struct {
+	// cells at least this high aren't in any basin
+	wall int
+	// whether diagonal neighbours flow into each other
+	diagonal bool
+}
+
+type Basin struct {
+	id   int
+	size int
+	// row, col of the lowest cell
+	low       [2]int
+	lowHeight int
+}
+
+type BasinMap struct {
+	rows, cols int
+	// basin id for every cell; -1 for walls
+	labels [][]int
+	basins []*Basin
+}
+
+// disjoint-set forest over cell indices
+type unionFind struct {
+	parent []int
+	size   []int
+}
+
+func newUnionFind(n int) *unionFind {
+	uf := &unionFind{
+		parent: make([]int, n),
+		size:   make([]int, n),
+	}
+
+	for i := range uf.parent {
+		uf.parent[i] = i
+		uf.size[i] = 1
+	}
+
+	return uf
+}
+
+// iterative with path halving, so there's no recursion to overflow
+func (uf *unionFind) find(x int) int {
+	for uf.parent[x] != x {
+		uf.parent[x] = uf.parent[uf.parent[x]]
+		x = uf.parent[x]
+	}
+
+	return x
+}
+
+// union by size
+func (uf *unionFind) union(a, b int) {
+	a, b = uf.find(a), uf.find(b)
+
+	if a == b {
+		return
+	}
+
+	if uf.size[a] < uf.size[b] {
+		a, b = b, a
+	}
+
+	uf.parent[b] = a
+	uf.size[a] += uf.size[b]
+}
+
+// labels every cell with its basin: neighbouring cells below the wall
+// height are merged, so every cell is visited a constant number of times
+func (heights heightmap) basins(opts BasinOptions) *BasinMap {
+	rows := len(heights)
+	cols := 0
+
+	if rows > 0 {
+		cols = len(heights[0])
+	}
+
+	uf := newUnionFind(rows * cols)
+	isBasin := func(r, c int) bool {
+		return r >= 0 && r < rows && c >= 0 && c < cols && heights[r][c] < opts.wall
+	}
+
+	// only look forward: right, down (and the down diagonals)
+	forward := [][2]int{{0, 1}, {1, 0}}
+
+	if opts.diagonal {
+		forward = append(forward, [2]int{1, -1}, [2]int{1, 1})
+	}
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if !isBasin(r, c) {
+				continue
+			}
+
+			for _, dir := range forward {
+				r2, c2 := r+dir[0], c+dir[1]
+
+				if isBasin(r2, c2) {
+					uf.union(r*cols+c, r2*cols+c2)
+				}
+			}
+		}
+	}
+
+	basinMap := &BasinMap{
+		rows:   rows,
+		cols:   cols,
+		labels: make([][]int, rows),
+	}
+
+	// root -> basin, numbered in reading order
+	byRoot := map[int]*Basin{}
+
+	for r := 0; r < rows; r++ {
+		basinMap.labels[r] = make([]int, cols)
+
+		for c := 0; c < cols; c++ {
+			if !isBasin(r, c) {
+				basinMap.labels[r][c] = -1
+				continue
+			}
+
+			root := uf.find(r*cols + c)
+			b, ok := byRoot[root]
+
+			if !ok {
+				b = &Basin{
+					id:        len(basinMap.basins),
+					low:       [2]int{r, c},
+					lowHeight: heights[r][c],
+				}
+				byRoot[root] = b
+				basinMap.basins = append(basinMap.basins, b)
+			}
+
+			b.size++
+
+			if heights[r][c] < b.lowHeight {
+				b.low = [2]int{r, c}
+				b.lowHeight = heights[r][c]
+			}
+
+			basinMap.labels[r][c] = b.id
+		}
+	}
+
+	return basinMap
+}
+
+const basinSymbols = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// one character per cell: walls are '#', and basin ids wrap
+// around after 62 basins
+func (basinMap *BasinMap) String() string {
+	var sb strings.Builder
+
+	for _, row := range basinMap.labels {
+		for _, id := range row {
+			if id < 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte(basinSymbols[id%len(basinSymbols)])
+			}
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// a distinct-ish colour per basin: the golden angle spreads hues out
+func basinColor(id int) color.RGBA {
+	hue := math.Mod(float64(id)*137.508, 360)
+
+	return hsvToRGB(hue, 0.65, 0.95)
+}
+
+func hsvToRGB(hue, saturation, value float64) color.RGBA {
+	chroma := value * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := value - chroma
+
+	var r, g, b float64
+
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	scale := func(v float64) uint8 {
+		return uint8(math.Round((v + m) * 255))
+	}
+
+	return color.RGBA{scale(r), scale(g), scale(b), 255}
+}
+
+// one pixel per cell; walls are black
+func (basinMap *BasinMap) image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, basinMap.cols, basinMap.rows))
+
+	for r, row := range basinMap.labels {
+		for c, id := range row {
+			if id < 0 {
+				img.SetRGBA(c, r, color.RGBA{0, 0, 0, 255})
+			} else {
+				img.SetRGBA(c, r, basinColor(id))
+			}
+		}
+	}
+
+	return img
+}
+
+// .txt for the ascii map, otherwise an image
+func (basinMap *BasinMap) export(filename string) error {
+	if strings.HasSuffix(filename, ".txt") {
+		return os.WriteFile(filename, []byte(basinMap.String()), 0644)
+	}
+
+	return utils.SaveImage(filename, basinMap.image())
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"sort"
 	"strconv"
@@ -112,86 +113,22 @@ func PartOne(content []string) (output int, err error) {
 	return
 }
 
-type basin struct {
-	// row, col
-	included   map[int]map[int]bool
-	heights    heightmap
-	rows, cols int
-}
-
-func (b *basin) isIncluded(r, c int) bool {
-	return b.included[r] != nil && b.included[r][c]
-}
-
-func (b *basin) search(r, c int) {
-	// we only search cells we know are within the basin
-	if b.included[r] == nil {
-		b.included[r] = make(map[int]bool, b.cols)
-	}
-
-	b.included[r][c] = true
-
-	neighbours, err := b.heights.findNeighbours(r, c)
-
-	if err != nil {
-		panic(fmt.Sprintf("failed to find neighbours for %d, %d", r, c))
-	}
-
-	for _, coords := range neighbours {
-		r, c = coords[0], coords[1]
-		included := b.isIncluded(r, c)
-
-		if included || b.heights[r][c] == 9 {
-			continue
-		}
-
-		// search again!
-		b.search(r, c)
-	}
-}
-
-func (heights *heightmap) newBasin(lowpoint [2]int) (b basin) {
-	b.heights = *heights
-	b.rows = len((*heights)[0])
-	b.cols = len(*heights)
-	b.included = make(map[int]map[int]bool, b.rows)
-
-	b.search(lowpoint[0], lowpoint[1])
+// Find the three largest basins and multiply their sizes together
+func PartTwo(content []string) (output int, err error) {
+	h := newHeightMap(content)
 
-	return
-}
+	basinMap := h.basins(BasinOptions{wall: defaultWall})
 
-func (b *basin) getSize() (size int) {
-	for _, vals := range b.included {
-		size += len(vals)
+	if len(basinMap.basins) < 3 {
+		return -1, errors.New("expected at least three basins")
 	}
 
-	return
-}
-
-// basins stem from lowpoints and encompass all cumulative neighbours
-// until a neighbour is a 9
-func (heights *heightmap) getBasinSizes() (sizes []int, err error) {
-	lowpoints, err := heights.getLowPoints()
-
-	if err != nil {
-		return sizes, err
-	}
+	sizes := []int{}
 
-	for _, coords := range lowpoints {
-		b := heights.newBasin(coords)
-		sizes = append(sizes, b.getSize())
+	for _, b := range basinMap.basins {
+		sizes = append(sizes, b.size)
 	}
 
-	return
-}
-
-// Find the three largest basins and multiply their sizes together
-func PartTwo(content []string) (output int, err error) {
-	h := newHeightMap(content)
-
-	sizes, err := h.getBasinSizes()
-
 	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
 
 	// top three
@@ -204,6 +141,12 @@ func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	exportFlag := flag.String("export", "", "save the basin map as .txt, .png or .pgm")
+	wallFlag := flag.Int("wall", defaultWall, "height of the walls between basins")
+	diagonalFlag := flag.Bool("diagonal", false, "diagonal cells flow into each other")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -223,4 +166,15 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *exportFlag != "" {
+		basinMap := newHeightMap(data).basins(BasinOptions{
+			wall:     *wallFlag,
+			diagonal: *diagonalFlag,
+		})
+
+		if err := basinMap.export(*exportFlag); err != nil {
+			fmt.Println("failed to export basins", err)
+		}
+	}
 }
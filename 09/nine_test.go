@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -51,3 +52,71 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestBasinMap(t *testing.T) {
+	basinMap := newHeightMap(vals).basins(BasinOptions{wall: defaultWall})
+
+	expected := `00###11111
+0#222#1#11
+#22222#3#1
+22222#333#
+#2###33333
+`
+
+	if basinMap.String() != expected {
+		t.Logf("Answer should be\n%s\nbut got\n%s", expected, basinMap.String())
+		t.Fail()
+	}
+
+	// basins are numbered in reading order
+	lows := [][2]int{{0, 1}, {0, 9}, {2, 2}, {4, 6}}
+	sizes := []int{3, 9, 14, 9}
+
+	for id, b := range basinMap.basins {
+		if b.low != lows[id] || b.size != sizes[id] {
+			t.Logf("Basin %d should be low %v size %d, but got %v size %d", id, lows[id], sizes[id], b.low, b.size)
+			t.Fail()
+		}
+	}
+}
+
+func TestBasinOptions(t *testing.T) {
+	heights := newHeightMap([]string{
+		"190",
+		"919",
+		"091",
+	})
+
+	if count := len(heights.basins(BasinOptions{wall: 9}).basins); count != 5 {
+		t.Logf("Answer should be %d basins, but got %d", 5, count)
+		t.Fail()
+	}
+
+	// all connected through the middle
+	if count := len(heights.basins(BasinOptions{wall: 9, diagonal: true}).basins); count != 1 {
+		t.Logf("Answer should be %d diagonal basin, but got %d", 1, count)
+		t.Fail()
+	}
+
+	// 1s are walls too
+	if count := len(heights.basins(BasinOptions{wall: 1}).basins); count != 2 {
+		t.Logf("Answer should be %d basins, but got %d", 2, count)
+		t.Fail()
+	}
+}
+
+func TestHugeBasin(t *testing.T) {
+	// one basin, which used to overflow the recursive search
+	lines := make([]string, 1000)
+
+	for i := range lines {
+		lines[i] = strings.Repeat("1", 1000)
+	}
+
+	basinMap := newHeightMap(lines).basins(BasinOptions{wall: defaultWall})
+
+	if len(basinMap.basins) != 1 || basinMap.basins[0].size != 1000*1000 {
+		t.Log("expected a single basin of every cell")
+		t.Fail()
+	}
+}
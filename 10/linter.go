@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Linter struct {
+	// opener -> closer
+	closers map[rune]rune
+	// closer -> opener
+	openers map[rune]rune
+}
+
+// constructor-like function: each pair is an opener and its closer, like "()"
+func newLinter(pairs ...string) (*Linter, error) {
+	linter := &Linter{
+		closers: map[rune]rune{},
+		openers: map[rune]rune{},
+	}
+
+	for _, pair := range pairs {
+		runes := []rune(pair)
+
+		if len(runes) != 2 || runes[0] == runes[1] {
+			return nil, fmt.Errorf("invalid bracket pair %q", pair)
+		}
+
+		open, close := runes[0], runes[1]
+
+		for _, char := range runes {
+			if linter.isBracket(char) {
+				return nil, fmt.Errorf("%q is in more than one pair", char)
+			}
+		}
+
+		linter.closers[open] = close
+		linter.openers[close] = open
+	}
+
+	return linter, nil
+}
+
+func (linter *Linter) isBracket(char rune) bool {
+	_, isOpener := linter.closers[char]
+	_, isCloser := linter.openers[char]
+
+	return isOpener || isCloser
+}
+
+// LintError is the first bad closer in a line
+type LintError struct {
+	// 1-based
+	Column int
+	// the closer that should be here; 0 if nothing was open
+	Expected rune
+	Found    rune
+}
+
+func (err *LintError) Error() string {
+	if err.Expected == 0 {
+		return fmt.Sprintf("column %d: unexpected %q, nothing is open", err.Column, err.Found)
+	}
+
+	return fmt.Sprintf("column %d: expected %q, but found %q", err.Column, err.Expected, err.Found)
+}
+
+// an opener waiting for its closer
+type opened struct {
+	column int
+	char   rune
+}
+
+type Result struct {
+	line string
+	// nil unless the line is corrupted
+	err *LintError
+	// still open at the end of the line, innermost last
+	unclosed []opened
+}
+
+func (result *Result) isCorrupted() bool {
+	return result.err != nil
+}
+
+func (result *Result) isIncomplete() bool {
+	return result.err == nil && len(result.unclosed) > 0
+}
+
+// stops at the first corrupt character; anything that isn't a bracket is ignored
+func (linter *Linter) lint(line string) (result Result) {
+	result.line = line
+	column := 0
+
+	for _, char := range line {
+		column++
+
+		if _, ok := linter.closers[char]; ok {
+			result.unclosed = append(result.unclosed, opened{column, char})
+			continue
+		}
+
+		if _, ok := linter.openers[char]; !ok {
+			continue
+		}
+
+		n := len(result.unclosed)
+
+		if n == 0 {
+			result.err = &LintError{Column: column, Found: char}
+			return
+		}
+
+		last := result.unclosed[n-1]
+
+		if linter.closers[last.char] != char {
+			result.err = &LintError{
+				Column:   column,
+				Expected: linter.closers[last.char],
+				Found:    char,
+			}
+			return
+		}
+
+		result.unclosed = result.unclosed[:n-1]
+	}
+
+	return
+}
+
+// closers for everything left open, innermost first
+func (linter *Linter) autocomplete(result Result) string {
+	var sb strings.Builder
+
+	// in reverse order, because it's LIFO
+	for i := len(result.unclosed) - 1; i >= 0; i-- {
+		sb.WriteRune(linter.closers[result.unclosed[i].char])
+	}
+
+	return sb.String()
+}
+
+type EditKind int
+
+const (
+	Insert EditKind = iota
+	Delete
+	Replace
+)
+
+type Edit struct {
+	kind EditKind
+	// 1-based column in the original line; inserts go before it
+	column int
+	// the new character (for Insert and Replace)
+	char rune
+}
+
+func (edit Edit) String() string {
+	switch edit.kind {
+	case Insert:
+		return fmt.Sprintf("insert %q at column %d", edit.char, edit.column)
+	case Delete:
+		return fmt.Sprintf("delete column %d", edit.column)
+	}
+
+	return fmt.Sprintf("replace column %d with %q", edit.column, edit.char)
+}
+
+// the fewest inserts, deletes and replacements that balance the
+// whole line; interval dp, so O(n^3) over the brackets in the line
+func (linter *Linter) repair(line string) []Edit {
+	type bracket struct {
+		column int
+		char   rune
+	}
+
+	brackets := []bracket{}
+	column := 0
+
+	for _, char := range line {
+		column++
+
+		if linter.isBracket(char) {
+			brackets = append(brackets, bracket{column, char})
+		}
+	}
+
+	n := len(brackets)
+	end := column + 1
+
+	// cost of turning a and b into a matching pair (if possible), and how
+	pairCost := func(a, b rune) (cost int, open, close rune) {
+		closeA, aOpens := linter.closers[a]
+		openB, bCloses := linter.openers[b]
+
+		switch {
+		case aOpens && bCloses && closeA == b:
+			return 0, a, b
+		case aOpens:
+			return 1, a, closeA
+		case bCloses:
+			return 1, openB, b
+		}
+
+		// a closes and b opens: both have to change
+		return 2, linter.openers[a], a
+	}
+
+	// dp[i][j] is the cost to balance brackets[i:j]
+	dp := make([][]int, n+1)
+
+	for i := range dp {
+		dp[i] = make([]int, n+1)
+	}
+
+	for length := 1; length <= n; length++ {
+		for i := 0; i+length <= n; i++ {
+			j := i + length
+			// drop or close brackets[i] on its own
+			best := 1 + dp[i+1][j]
+
+			for k := i + 1; k < j; k++ {
+				cost, _, _ := pairCost(brackets[i].char, brackets[k].char)
+
+				if total := cost + dp[i+1][k] + dp[k+1][j]; total < best {
+					best = total
+				}
+			}
+
+			dp[i][j] = best
+		}
+	}
+
+	edits := []Edit{}
+
+	// walk back through the choices that made dp[0][n]
+	var rebuild func(i, j, after int)
+
+	rebuild = func(i, j, after int) {
+		if i >= j {
+			return
+		}
+
+		b := brackets[i]
+
+		for k := i + 1; k < j; k++ {
+			cost, open, close := pairCost(b.char, brackets[k].char)
+
+			if cost+dp[i+1][k]+dp[k+1][j] == dp[i][j] {
+				if open != b.char {
+					edits = append(edits, Edit{Replace, b.column, open})
+				}
+
+				if close != brackets[k].char {
+					edits = append(edits, Edit{Replace, brackets[k].column, close})
+				}
+
+				rebuild(i+1, k, brackets[k].column)
+				rebuild(k+1, j, after)
+
+				return
+			}
+		}
+
+		// unpaired: close an opener at the end of its span, drop a closer
+		if close, ok := linter.closers[b.char]; ok {
+			edits = append(edits, Edit{Insert, after, close})
+		} else {
+			edits = append(edits, Edit{Delete, b.column, 0})
+		}
+
+		rebuild(i+1, j, after)
+	}
+
+	rebuild(0, n, end)
+
+	// stable, so edits at the same column stay in the order they were found
+	sort.SliceStable(edits, func(a, b int) bool {
+		return edits[a].column < edits[b].column
+	})
+
+	return edits
+}
+
+// applies edits from repair to the line
+func applyEdits(line string, edits []Edit) string {
+	runes := []rune(line)
+	var sb strings.Builder
+	e := 0
+
+	for column := 1; column <= len(runes)+1; column++ {
+		deleted := false
+		replaced := rune(0)
+
+		for ; e < len(edits) && edits[e].column == column; e++ {
+			switch edits[e].kind {
+			case Insert:
+				sb.WriteRune(edits[e].char)
+			case Delete:
+				deleted = true
+			case Replace:
+				replaced = edits[e].char
+			}
+		}
+
+		if column > len(runes) || deleted {
+			continue
+		}
+
+		if replaced != 0 {
+			sb.WriteRune(replaced)
+		} else {
+			sb.WriteRune(runes[column-1])
+		}
+	}
+
+	return sb.String()
+}
+
+// Scoring turns lint results into points
+type Scoring struct {
+	corrupt      func(err *LintError) int
+	autocomplete func(completion string) int
+}
+
+// the puzzle's points
+var (
+	corruptPoints      = map[rune]int{')': 3, ']': 57, '}': 1197, '>': 25137}
+	autocompletePoints = map[rune]int{')': 1, ']': 2, '}': 3, '>': 4}
+)
+
+var puzzleScoring = Scoring{
+	corrupt: func(err *LintError) int {
+		return corruptPoints[err.Found]
+	},
+	autocomplete: func(completion string) (score int) {
+		for _, char := range completion {
+			score = score*5 + autocompletePoints[char]
+		}
+
+		return
+	},
+}
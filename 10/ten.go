@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"sort"
 
@@ -10,98 +12,23 @@ import (
 // different puzzles require different file loaders
 var FileLoader = utils.LoadAsLines
 
-type Bracket struct {
-	isOpen                          bool
-	pair                            rune
-	corruptScore, autocompleteScore int
-}
-
-var brackets = map[rune]Bracket{
-	'(': {pair: ')', isOpen: true},
-	'[': {pair: ']', isOpen: true},
-	'{': {pair: '}', isOpen: true},
-	'<': {pair: '>', isOpen: true},
-	')': {pair: '(', corruptScore: 3, autocompleteScore: 1},
-	']': {pair: '[', corruptScore: 57, autocompleteScore: 2},
-	'}': {pair: '{', corruptScore: 1197, autocompleteScore: 3},
-	'>': {pair: '<', corruptScore: 25137, autocompleteScore: 4},
-}
-
-// TODO: replace with types.Stack[T]?
-type Stack []rune
-
-func (stack *Stack) pop() (out rune) {
-	lastIndex := len(*stack) - 1
-	out = (*stack)[lastIndex]
-
-	*stack = (*stack)[:lastIndex]
-
-	return
-}
-
-type Line struct {
-	isCorrupted, isIncomplete bool
-	corruptedBy               rune
-	incompleteBrackets        Stack
-}
-
-// this constructor is actually doing all the heavy lifting
-func newLine(line string) Line {
-	stack := Stack{}
-
-	for _, char := range line {
-		bracket := brackets[char]
-
-		if bracket.isOpen {
-			stack = append(stack, char)
-		} else {
-			lastChar := stack.pop()
-
-			if lastChar != bracket.pair {
-				return Line{
-					isCorrupted: true,
-					corruptedBy: char,
-				}
-			}
-		}
-	}
-
-	return Line{
-		isIncomplete:       true,
-		incompleteBrackets: stack,
-	}
-}
+// the puzzle's brackets
+var puzzlePairs = []string{"()", "[]", "{}", "<>"}
 
 // Find the first illegal character in each corrupted line of the navigation subsystem
 func PartOne(content []string) (output int, err error) {
-	for _, line := range content {
-		l := newLine(line)
-
-		if l.isCorrupted {
-			output += brackets[l.corruptedBy].corruptScore
-		}
-	}
+	linter, err := newLinter(puzzlePairs...)
 
-	return
-}
-
-// gets all pairs of incomplete brackets
-func (line *Line) getAutocomplete() (autocomplete string) {
-	incomplete := line.incompleteBrackets
-
-	// in reverse order, because it's LIFO
-	for i := len(incomplete) - 1; i >= 0; i-- {
-		char := incomplete[i]
-		autocomplete += string(brackets[char].pair)
+	if err != nil {
+		return -1, err
 	}
 
-	return
-}
+	for _, line := range content {
+		result := linter.lint(line)
 
-func getAutocompleteScore(autocomplete string) (score int) {
-	for _, char := range autocomplete {
-		score *= 5
-		score += brackets[char].autocompleteScore
+		if result.isCorrupted() {
+			output += puzzleScoring.corrupt(result.err)
+		}
 	}
 
 	return
@@ -110,17 +37,27 @@ func getAutocompleteScore(autocomplete string) (score int) {
 // Find the completion string for each incomplete line, score the completion strings,
 // sort the scores, and return the middle score (always odd number)
 func PartTwo(content []string) (output int, err error) {
+	linter, err := newLinter(puzzlePairs...)
+
+	if err != nil {
+		return -1, err
+	}
+
 	points := []int{}
 
 	for _, line := range content {
-		l := newLine(line)
+		result := linter.lint(line)
 
-		if l.isIncomplete {
-			score := getAutocompleteScore(l.getAutocomplete())
+		if result.isIncomplete() {
+			score := puzzleScoring.autocomplete(linter.autocomplete(result))
 			points = append(points, score)
 		}
 	}
 
+	if len(points) == 0 {
+		return -1, errors.New("no incomplete lines")
+	}
+
 	sort.Ints(points)
 
 	// always odd, so len / 2 should work
@@ -133,6 +70,10 @@ func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	lintFlag := flag.Bool("lint", false, "print every error and how to fix it")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -152,4 +93,22 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *lintFlag {
+		printLint(data)
+	}
+}
+
+func printLint(content []string) {
+	linter, _ := newLinter(puzzlePairs...)
+
+	for i, line := range content {
+		result := linter.lint(line)
+
+		if result.isCorrupted() {
+			fmt.Printf("line %d: %v; fix: %v \n", i+1, result.err, linter.repair(line))
+		} else if result.isIncomplete() {
+			fmt.Printf("line %d: incomplete; complete with %q \n", i+1, linter.autocomplete(result))
+		}
+	}
 }
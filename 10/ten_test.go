@@ -51,3 +51,99 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestLintErrors(t *testing.T) {
+	linter, _ := newLinter(puzzlePairs...)
+
+	tests := map[string]string{
+		"{([(<{}[<>[]}>{[]{[(<()>": `column 13: expected ']', but found '}'`,
+		// used to panic popping an empty stack
+		"())":       `column 3: unexpected ')', nothing is open`,
+		"<a (b) c]": `column 9: expected '>', but found ']'`,
+	}
+
+	for line, expected := range tests {
+		result := linter.lint(line)
+
+		if !result.isCorrupted() || result.err.Error() != expected {
+			t.Logf("%q should fail with %q, but got %v", line, expected, result.err)
+			t.Fail()
+		}
+	}
+}
+
+func TestCustomPairs(t *testing.T) {
+	linter, err := newLinter("«»", "()")
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.FailNow()
+	}
+
+	result := linter.lint("«(«")
+
+	if !result.isIncomplete() || linter.autocomplete(result) != "»)»" {
+		t.Logf("Answer should be %q, but got %q", "»)»", linter.autocomplete(result))
+		t.Fail()
+	}
+
+	if _, err := newLinter("()", ")("); err == nil {
+		t.Log("expected an error for overlapping pairs")
+		t.Fail()
+	}
+}
+
+func TestRepair(t *testing.T) {
+	linter, _ := newLinter(puzzlePairs...)
+
+	tests := []struct {
+		line     string
+		edits    int
+		repaired string
+	}{
+		{"())", 1, "()"},
+		{"(]", 1, "()"},
+		{"{()()()>", 1, "{()()()}"},
+		{"(()", 1, "(())"},
+		{")(", 2, "()"},
+	}
+
+	for _, test := range tests {
+		edits := linter.repair(test.line)
+		repaired := applyEdits(test.line, edits)
+
+		if len(edits) != test.edits || repaired != test.repaired {
+			t.Logf("%q should be repaired to %q in %d edits, but got %q with %v", test.line, test.repaired, test.edits, repaired, edits)
+			t.Fail()
+		}
+	}
+
+	// every example line ends up valid
+	for _, line := range vals {
+		repaired := applyEdits(line, linter.repair(line))
+		result := linter.lint(repaired)
+
+		if result.isCorrupted() || result.isIncomplete() {
+			t.Logf("%q should be balanced", repaired)
+			t.Fail()
+		}
+	}
+}
+
+func TestCustomScoring(t *testing.T) {
+	linter, _ := newLinter(puzzlePairs...)
+
+	// one point per missing closer
+	scoring := Scoring{
+		autocomplete: func(completion string) int {
+			return len(completion)
+		},
+	}
+
+	result := linter.lint("[({(<(())[]>[[{[]{<()<>>")
+
+	if score := scoring.autocomplete(linter.autocomplete(result)); score != 8 {
+		t.Logf("Answer should be %d, but got %d", 8, score)
+		t.Fail()
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import "fmt"
+
+type Cycle struct {
+	// the state after `start` steps is the first one that repeats
+	start int
+	// it repeats every `period` steps
+	period int
+	// whether every octopus flashes together during the cycle
+	synchronized bool
+}
+
+// custom string representation
+func (cycle Cycle) String() string {
+	output := fmt.Sprintf("starts after step %d, repeats every %d steps", cycle.start, cycle.period)
+
+	if cycle.synchronized {
+		output += " (synchronized)"
+	}
+
+	return output
+}
+
+// copies the energy; neighbours never change, so they're shared
+func (grid *Grid) clone() *Grid {
+	copied := *grid
+	copied.energy = append([]int(nil), grid.energy...)
+
+	return &copied
+}
+
+func (grid *Grid) sameState(other *Grid) bool {
+	for i, energy := range grid.energy {
+		if other.energy[i] != energy {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Brent's algorithm: finds the first repeated state, looking at most
+// limit steps ahead, while only holding two copies of the grid;
+// the grid itself isn't changed
+func (grid *Grid) findCycle(limit int) (cycle Cycle, ok bool) {
+	// find the period: the hare runs ahead, and the tortoise
+	// teleports to it at every power of two
+	power, period := 1, 1
+	tortoise := grid.clone()
+	hare := grid.clone()
+	hare.step()
+
+	for !tortoise.sameState(hare) {
+		if hare.steps-grid.steps >= limit {
+			return
+		}
+
+		if power == period {
+			tortoise = hare.clone()
+			power *= 2
+			period = 0
+		}
+
+		hare.step()
+		period++
+	}
+
+	// find the start: walk two grids, `period` steps apart,
+	// until they meet
+	tortoise = grid.clone()
+	hare = grid.clone()
+
+	for i := 0; i < period; i++ {
+		hare.step()
+	}
+
+	for !tortoise.sameState(hare) {
+		tortoise.step()
+		hare.step()
+	}
+
+	cycle = Cycle{start: tortoise.steps, period: period}
+
+	// check one lap of the cycle for a full flash
+	for i := 0; i < period; i++ {
+		if len(tortoise.step()) == tortoise.size() {
+			cycle.synchronized = true
+		}
+	}
+
+	return cycle, true
+}
@@ -1,8 +1,9 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
@@ -11,221 +12,266 @@ import (
 // different puzzles require different file loaders
 var FileLoader = utils.LoadAsLines
 
-const (
-	size      = 10
-	maxIndex  = 9
-	maxEnergy = 9
+// the puzzle's octopuses flash above 9
+const defaultThreshold = 9
+
+// row, col offsets to a cell's neighbours
+type Neighbourhood [][2]int
+
+var (
+	// all 8 surrounding cells (the puzzle's)
+	Moore = Neighbourhood{
+		{-1, -1}, {-1, 0}, {-1, 1},
+		{0, -1}, {0, 1},
+		{1, -1}, {1, 0}, {1, 1},
+	}
+	// only up, down, left and right
+	VonNeumann = Neighbourhood{{-1, 0}, {0, -1}, {0, 1}, {1, 0}}
 )
 
-type Cell struct {
-	energy     int
-	flashed    bool
-	neighbours []*Cell
+type GridOptions struct {
+	// an octopus flashes when its energy goes above this
+	threshold     int
+	neighbourhood Neighbourhood
 }
 
-type Grid struct {
-	cells [size][size]*Cell
+var puzzleOptions = GridOptions{
+	threshold:     defaultThreshold,
+	neighbourhood: Moore,
 }
 
-// custom string representation
-func (grid *Grid) String() (output string) {
-	output += "[[ "
-	for r, cells := range grid.cells {
-		for _, cell := range cells {
-			output += cell.String()
-		}
+// cells are flat: cell i is at row i/cols, col i%cols
+type Grid struct {
+	rows, cols int
+	threshold  int
+	energy     []int
+	// cell index -> indices of its neighbours
+	neighbours [][]int
+	// how many steps have been taken
+	steps int
+}
 
-		if r < size-1 {
-			output += "\n   "
-		}
+// constructor-like function: any rectangular grid of digits
+func newGridWithOptions(data []string, opts GridOptions) (*Grid, error) {
+	if opts.threshold < 0 {
+		return nil, errors.New("threshold can't be negative")
 	}
-	output += " ]]"
 
-	return
-}
+	// ignore trailing blank lines
+	for len(data) > 0 && data[len(data)-1] == "" {
+		data = data[:len(data)-1]
+	}
 
-// custom string representation
-func (cell *Cell) String() string {
-	return fmt.Sprint(cell.energy)
-}
+	if len(data) == 0 {
+		return nil, errors.New("grid is empty")
+	}
 
-// this is the constructor-like function we are using
-func newGridPointer(data []string) *Grid {
-	grid := &Grid{}
+	grid := &Grid{
+		rows:      len(data),
+		cols:      len(data[0]),
+		threshold: opts.threshold,
+	}
 
 	for r, line := range data {
-		chars := strings.Split(line, "")
-		for c, char := range chars {
-			num, err := strconv.Atoi(char)
+		if len(line) != grid.cols {
+			return nil, fmt.Errorf("row %d has %d cells, expected %d", r+1, len(line), grid.cols)
+		}
 
-			if err != nil {
-				panic("could not convert char to num in grid")
+		for c := 0; c < len(line); c++ {
+			char := line[c]
+
+			if char < '0' || char > '9' {
+				return nil, fmt.Errorf("row %d, col %d: %q is not a digit", r+1, c+1, char)
 			}
 
-			grid.cells[r][c] = &Cell{energy: num}
+			grid.energy = append(grid.energy, int(char-'0'))
 		}
 	}
 
-	grid.updateNeighbours()
+	grid.updateNeighbours(opts.neighbourhood)
 
-	return grid
+	return grid, nil
 }
 
-// IGNORE: this was added just for benchmarking (see BLOG.md)
-func newGrid(data []string) (grid Grid) {
-	for r, line := range data {
-		chars := strings.Split(line, "")
-		for c, char := range chars {
-			num, err := strconv.Atoi(char)
-
-			if err != nil {
-				panic("could not convert char to num in grid")
-			}
+// this is the constructor-like function we are using
+func newGridPointer(data []string) *Grid {
+	grid, err := newGridWithOptions(data, puzzleOptions)
 
-			grid.cells[r][c] = &Cell{energy: num}
-		}
+	if err != nil {
+		panic(err)
 	}
 
-	grid.updateNeighbours()
-
 	return grid
 }
 
-func (grid *Grid) updateNeighbours() {
-	for r, row := range grid.cells {
-		for c, cell := range row {
-			indices := [...][2]int{
-				{r - 1, c - 1},
-				{r - 1, c},
-				{r - 1, c + 1},
-				{r, c - 1},
-				{r, c + 1},
-				{r + 1, c - 1},
-				{r + 1, c},
-				{r + 1, c + 1},
-			}
+// IGNORE: this was added just for benchmarking (see BLOG.md)
+func newGrid(data []string) (grid Grid) {
+	return *newGridPointer(data)
+}
+
+func (grid *Grid) updateNeighbours(neighbourhood Neighbourhood) {
+	grid.neighbours = make([][]int, len(grid.energy))
 
-			for _, coords := range indices {
-				r, c := coords[0], coords[1]
+	for i := range grid.energy {
+		r, c := i/grid.cols, i%grid.cols
 
-				if r < 0 || c < 0 || r > maxIndex || c > maxIndex {
-					continue
-				}
+		for _, offset := range neighbourhood {
+			r2, c2 := r+offset[0], c+offset[1]
 
-				cell.neighbours = append(cell.neighbours, grid.cells[r][c])
+			if r2 < 0 || c2 < 0 || r2 >= grid.rows || c2 >= grid.cols {
+				continue
 			}
+
+			grid.neighbours[i] = append(grid.neighbours[i], r2*grid.cols+c2)
 		}
 	}
 }
 
-// This increases the energy level of all adjacent octopuses by 1
-// If this causes an octopus to have an energy level greater than 9, it also flashes.
-func (cell *Cell) flash() {
-	cell.flashed = true
+// custom string representation
+func (grid *Grid) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("[[ ")
 
-	for _, neighbour := range cell.neighbours {
-		neighbour.energy++
-		if neighbour.energy > maxEnergy && !neighbour.flashed {
-			neighbour.flash()
+	for i, energy := range grid.energy {
+		if i > 0 && i%grid.cols == 0 {
+			sb.WriteString("\n   ")
 		}
+
+		sb.WriteString(fmt.Sprint(energy))
 	}
+
+	sb.WriteString(" ]]")
+
+	return sb.String()
 }
 
-// any octopus that flashed during has its
-// energy level set to 0
-func (cell *Cell) resolveFlash() {
-	cell.flashed = false
-	cell.energy = 0
+func (grid *Grid) size() int {
+	return len(grid.energy)
 }
 
-// TODO: slice should be pointer
-func (grid *Grid) getAllCells() (cells []*Cell) {
-	for _, row := range grid.cells {
-		for _, cell := range row {
-			cells = append(cells, cell)
+// one step; returns the indices of every cell that flashed,
+// in the order they flashed
+func (grid *Grid) step() (flashed []int) {
+	// a stack instead of recursion, so big grids can't overflow
+	stack := []int{}
+
+	// First, the energy level of each octopus increases by 1.
+	for i := range grid.energy {
+		grid.energy[i]++
+
+		// the input can start above the threshold
+		if grid.energy[i] > grid.threshold {
+			stack = append(stack, i)
 		}
 	}
 
+	// every cell goes above the threshold exactly once,
+	// which is when it flashes
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		flashed = append(flashed, i)
+
+		for _, n := range grid.neighbours[i] {
+			grid.energy[n]++
+
+			if grid.energy[n] == grid.threshold+1 {
+				stack = append(stack, n)
+			}
+		}
+	}
+
+	// any octopus that flashed has its energy level set to 0
+	for _, i := range flashed {
+		grid.energy[i] = 0
+	}
+
+	grid.steps++
+
 	return
 }
 
 func (grid *Grid) update() (flashes int) {
-	// this is making me second guess my 2d array
-	cells := grid.getAllCells()
+	return len(grid.step())
+}
 
-	// First, the energy level of each octopus increases by 1.
-	for _, cell := range cells {
-		cell.energy++
-	}
+// calls fn after every step (1-indexed) with the cells that flashed,
+// until fn returns false
+func (grid *Grid) each(fn func(step int, flashed []int) bool) {
+	for {
+		flashed := grid.step()
 
-	// any octopus with an energy level greater than 9 flashes
-	for _, cell := range cells {
-		// check for cell.flashed because
-		// it may have been flashed by a neighbour
-		// ...scandalous!
-		if cell.energy > maxEnergy && !cell.flashed {
-			cell.flash()
+		if !fn(grid.steps, flashed) {
+			return
 		}
 	}
+}
 
-	// wanted to make this a deferred statement, but no
-	for _, cell := range cells {
-		if cell.flashed {
-			flashes++
-			cell.resolveFlash()
+// first step where every octopus flashes, within limit steps
+func (grid *Grid) synchronized(limit int) (int, error) {
+	found := -1
+
+	grid.each(func(step int, flashed []int) bool {
+		if len(flashed) == grid.size() {
+			found = step
+			return false
 		}
+
+		return step < limit
+	})
+
+	if found == -1 {
+		return 0, fmt.Errorf("octopuses never synchronized within %d steps", limit)
 	}
 
-	return
+	return found, nil
 }
 
 // How many total flashes are there after 100 steps?
 func PartOne(content []string) (output int, err error) {
-	grid := newGridPointer(content)
+	grid, err := newGridWithOptions(content, puzzleOptions)
 
-	for i := 0; i < 100; i++ {
-		output += grid.update()
+	if err != nil {
+		return
 	}
 
+	grid.each(func(step int, flashed []int) bool {
+		output += len(flashed)
+
+		return step < 100
+	})
+
 	return
 }
 
 func PartTwo(content []string) (output int, err error) {
-	grid := newGridPointer(content)
-
-	// set an upper bound of 1K?
-	for i := 0; i < 1000; i++ {
-		flashes := grid.update()
+	grid, err := newGridWithOptions(content, puzzleOptions)
 
-		if flashes == size*size {
-			// return value is not 0-indexed
-			return i + 1, nil
-		}
+	if err != nil {
+		return
 	}
 
-	return
+	// set an upper bound of 1K?
+	return grid.synchronized(1000)
 }
 
 func PartTwoValue(content []string) (output int, err error) {
 	grid := newGrid(content)
 
-	// set an upper bound of 1K?
-	for i := 0; i < 1000; i++ {
-		flashes := grid.update()
-
-		if flashes == size*size {
-			// return value is not 0-indexed
-			return i + 1, nil
-		}
-	}
-
-	return
+	return grid.synchronized(1000)
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	thresholdFlag := flag.Int("threshold", defaultThreshold, "energy an octopus flashes above")
+	vonNeumannFlag := flag.Bool("von-neumann", false, "only flash up, down, left and right")
+	cycleFlag := flag.Int("cycle", 0, "look for a cycle within this many steps")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -245,4 +291,30 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *cycleFlag <= 0 {
+		return
+	}
+
+	opts := GridOptions{threshold: *thresholdFlag, neighbourhood: Moore}
+
+	if *vonNeumannFlag {
+		opts.neighbourhood = VonNeumann
+	}
+
+	grid, err := newGridWithOptions(data, opts)
+
+	if err != nil {
+		fmt.Println("failed to load grid", err)
+		return
+	}
+
+	cycle, ok := grid.findCycle(*cycleFlag)
+
+	if !ok {
+		fmt.Printf("No cycle within %d steps \n", *cycleFlag)
+		return
+	}
+
+	fmt.Printf("Cycle: %s \n", cycle)
 }
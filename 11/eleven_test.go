@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -63,3 +64,94 @@ func BenchmarkGridPointer(b *testing.B) {
 		PartTwo(vals)
 	}
 }
+
+func TestStepFlashes(t *testing.T) {
+	grid, err := newGridWithOptions([]string{
+		"11111",
+		"19991",
+		"19191",
+		"19991",
+		"11111",
+	}, puzzleOptions)
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+	}
+
+	if flashed := grid.step(); len(flashed) != 9 {
+		t.Logf("expected 9 flashes on step 1, got %v", flashed)
+		t.Fail()
+	}
+
+	if flashed := grid.step(); len(flashed) != 0 {
+		t.Logf("expected no flashes on step 2, got %v", flashed)
+		t.Fail()
+	}
+
+	expected := "[[ 45654\n   51115\n   61116\n   51115\n   45654 ]]"
+
+	if grid.String() != expected {
+		t.Logf("expected %s, got %s", expected, grid)
+		t.Fail()
+	}
+}
+
+func TestRectangularGrid(t *testing.T) {
+	grid, err := newGridWithOptions([]string{"1234"}, GridOptions{3, VonNeumann})
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+	}
+
+	expected := [][]int{{3, 2, 1}, {0}, {}, {1, 2, 3}}
+
+	grid.each(func(step int, flashed []int) bool {
+		if fmt.Sprint(flashed) != fmt.Sprint(expected[step-1]) {
+			t.Logf("step %d: expected %v, got %v", step, expected[step-1], flashed)
+			t.Fail()
+		}
+
+		return step < len(expected)
+	})
+
+	if _, err := newGridWithOptions([]string{"123", "45"}, puzzleOptions); err == nil {
+		t.Log("expected an error for a ragged grid")
+		t.Fail()
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	tests := []struct {
+		data     []string
+		opts     GridOptions
+		expected Cycle
+	}{
+		{vals, puzzleOptions, Cycle{195, 10, true}},
+		{[]string{"1234"}, GridOptions{3, VonNeumann}, Cycle{1, 3, false}},
+		{[]string{"12", "34", "50"}, GridOptions{5, VonNeumann}, Cycle{33, 6, true}},
+	}
+
+	for _, test := range tests {
+		grid, _ := newGridWithOptions(test.data, test.opts)
+		cycle, ok := grid.findCycle(10000)
+
+		if !ok || cycle != test.expected {
+			t.Logf("expected %v, got %v (%v)", test.expected, cycle, ok)
+			t.Fail()
+		}
+
+		if grid.steps != 0 {
+			t.Log("findCycle shouldn't step the grid")
+			t.Fail()
+		}
+	}
+
+	grid := newGridPointer(vals)
+
+	if _, ok := grid.findCycle(100); ok {
+		t.Log("expected no cycle within 100 steps")
+		t.Fail()
+	}
+}
@@ -14,14 +14,18 @@ const (
 type Cave struct {
 	name  string
 	isBig bool
+	// small caves get their own bit, for sets of visited caves; 0 for big caves
+	bit uint64
 	// TODO: should be pointer?
 	flowsInto []*Cave
 }
 
 type CaveSystem struct {
-	caves                    map[string]*Cave
-	viewSingleSmallCaveTwice bool
-	paths                    Paths
+	caves map[string]*Cave
+	// how many times, in total, a path can go back into small caves
+	// it already visited (Part Two is 1)
+	revisits   int
+	smallCaves int
 }
 
 // constructor-like function to create CaveSystem
@@ -57,6 +61,15 @@ func (caveSys *CaveSystem) getOrCreateCave(name string) (cave *Cave) {
 			isBig: isUpperCaseLetter(name),
 		}
 
+		if !cave.isBig {
+			// caves past the 64th don't get a bit; countPaths refuses them
+			if caveSys.smallCaves < 64 {
+				cave.bit = 1 << caveSys.smallCaves
+			}
+
+			caveSys.smallCaves++
+		}
+
 		caveSys.caves[name] = cave
 	}
 
@@ -82,33 +95,6 @@ func (cave *Cave) connect(nextCave *Cave) {
 	}
 }
 
-// recursive function to continually navigate through connected caves
-// saves all paths that end in "end" to the CaveSystem.paths
-func (caveSys *CaveSystem) traverse(path Path) {
-	lastCave := path[len(path)-1]
-
-	for _, nextCave := range lastCave.flowsInto {
-		if !path.canCaveBeVisited(nextCave, caveSys.viewSingleSmallCaveTwice) {
-			continue
-		}
-
-		updatedPath := append(path, nextCave)
-		if nextCave.name == end {
-			// nested append with the spread syntax creates a copy of the slice
-			caveSys.paths = append(caveSys.paths, append(Path{}, updatedPath...))
-		} else {
-			caveSys.traverse(updatedPath)
-		}
-	}
-}
-
-// begin finding all paths in the cave system, by starting at the start
-func (caveSys *CaveSystem) findAllPaths() (count int) {
-	caveSys.traverse(Path{caveSys.caves[start]})
-
-	return len(caveSys.paths)
-}
-
 // custom string representation for CaveSystem
 func (caveSys *CaveSystem) String() (output string) {
 	output += "CaveSystem {"
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// an edge from one cave to the one it flows into
+type edge struct {
+	from, to *Cave
+}
+
+// caves sorted by name, so exports don't change between runs
+func (caveSys *CaveSystem) sortedCaves() []*Cave {
+	caves := make([]*Cave, 0, len(caveSys.caves))
+
+	for _, cave := range caveSys.caves {
+		caves = append(caves, cave)
+	}
+
+	sort.Slice(caves, func(i, j int) bool {
+		return caves[i].name < caves[j].name
+	})
+
+	return caves
+}
+
+// turns "start,A,end" into a Path, checking every step is a real edge
+func (caveSys *CaveSystem) parsePath(str string) (Path, error) {
+	path := Path{}
+
+	for _, name := range strings.Split(str, ",") {
+		cave, ok := caveSys.caves[strings.TrimSpace(name)]
+
+		if !ok {
+			return nil, fmt.Errorf("no cave named %q", name)
+		}
+
+		path = append(path, cave)
+	}
+
+	return path, caveSys.checkPath(path)
+}
+
+func (caveSys *CaveSystem) checkPath(path Path) error {
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		ok := false
+
+		for _, nextCave := range from.flowsInto {
+			if nextCave == to {
+				ok = true
+				break
+			}
+		}
+
+		if !ok {
+			return fmt.Errorf("%s doesn't flow into %s", from.name, to.name)
+		}
+	}
+
+	return nil
+}
+
+// which steps of the path (1-based) use each edge; and the caves on it
+func pathSteps(path Path) (steps map[edge][]int, caves map[*Cave]bool) {
+	steps = map[edge][]int{}
+	caves = map[*Cave]bool{}
+
+	for i, cave := range path {
+		caves[cave] = true
+
+		if i > 0 {
+			key := edge{path[i-1], cave}
+			steps[key] = append(steps[key], i)
+		}
+	}
+
+	return
+}
+
+func joinSteps(steps []int) string {
+	strs := make([]string, len(steps))
+
+	for i, step := range steps {
+		strs[i] = fmt.Sprint(step)
+	}
+
+	return strings.Join(strs, ",")
+}
+
+// Graphviz: big caves are boxes, small caves are ellipses, start and
+// end are double circles; an overlay path is drawn in red, and its
+// edges are labelled with the steps that use them
+func (caveSys *CaveSystem) dot(overlay Path) (string, error) {
+	if err := caveSys.checkPath(overlay); err != nil {
+		return "", err
+	}
+
+	steps, onPath := pathSteps(overlay)
+	caves := caveSys.sortedCaves()
+
+	var sb strings.Builder
+
+	sb.WriteString("digraph caves {\n")
+	sb.WriteString("  rankdir=LR;\n")
+
+	for _, cave := range caves {
+		shape := "ellipse"
+
+		switch {
+		case cave.name == start || cave.name == end:
+			shape = "doublecircle"
+		case cave.isBig:
+			shape = "box"
+		}
+
+		attrs := fmt.Sprintf("shape=%s", shape)
+
+		if onPath[cave] {
+			attrs += `, color=red, style=bold`
+		}
+
+		fmt.Fprintf(&sb, "  %q [%s];\n", cave.name, attrs)
+	}
+
+	for _, cave := range caves {
+		for _, nextCave := range cave.flowsInto {
+			fmt.Fprintf(&sb, "  %q -> %q", cave.name, nextCave.name)
+
+			if used, ok := steps[edge{cave, nextCave}]; ok {
+				fmt.Fprintf(&sb, " [color=red, penwidth=2, label=%q]", joinSteps(used))
+			}
+
+			sb.WriteString(";\n")
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String(), nil
+}
+
+// Mermaid flowchart: same idea as dot; nodes get generated ids so
+// any cave name is safe
+func (caveSys *CaveSystem) mermaid(overlay Path) (string, error) {
+	if err := caveSys.checkPath(overlay); err != nil {
+		return "", err
+	}
+
+	steps, onPath := pathSteps(overlay)
+	caves := caveSys.sortedCaves()
+	ids := map[*Cave]string{}
+
+	var sb strings.Builder
+
+	sb.WriteString("flowchart LR\n")
+
+	for i, cave := range caves {
+		id := fmt.Sprintf("c%d", i)
+		ids[cave] = id
+
+		switch {
+		case cave.name == start || cave.name == end:
+			fmt.Fprintf(&sb, "  %s((%q))\n", id, cave.name)
+		case cave.isBig:
+			fmt.Fprintf(&sb, "  %s[%q]\n", id, cave.name)
+		default:
+			fmt.Fprintf(&sb, "  %s([%q])\n", id, cave.name)
+		}
+	}
+
+	// linkStyle refers to edges by the order they're declared
+	highlighted := []string{}
+	link := 0
+
+	for _, cave := range caves {
+		for _, nextCave := range cave.flowsInto {
+			if used, ok := steps[edge{cave, nextCave}]; ok {
+				fmt.Fprintf(&sb, "  %s -->|%s| %s\n", ids[cave], joinSteps(used), ids[nextCave])
+				highlighted = append(highlighted, fmt.Sprint(link))
+			} else {
+				fmt.Fprintf(&sb, "  %s --> %s\n", ids[cave], ids[nextCave])
+			}
+
+			link++
+		}
+	}
+
+	sb.WriteString("  classDef big fill:#ddd,stroke:#333\n")
+	sb.WriteString("  classDef small fill:#fff,stroke:#333\n")
+	sb.WriteString("  classDef terminal fill:#cfc,stroke:#333\n")
+	sb.WriteString("  classDef onPath stroke:red,stroke-width:3px\n")
+
+	for _, cave := range caves {
+		class := "small"
+
+		switch {
+		case cave.name == start || cave.name == end:
+			class = "terminal"
+		case cave.isBig:
+			class = "big"
+		}
+
+		fmt.Fprintf(&sb, "  class %s %s\n", ids[cave], class)
+
+		if onPath[cave] {
+			fmt.Fprintf(&sb, "  class %s onPath\n", ids[cave])
+		}
+	}
+
+	if len(highlighted) > 0 {
+		fmt.Fprintf(&sb, "  linkStyle %s stroke:red,stroke-width:3px\n", strings.Join(highlighted, ","))
+	}
+
+	return sb.String(), nil
+}
+
+// .dot or .gv for Graphviz, .mmd for Mermaid; overlay can be nil
+func (caveSys *CaveSystem) export(filename string, overlay Path) error {
+	var output string
+	var err error
+
+	switch filepath.Ext(filename) {
+	case ".dot", ".gv":
+		output, err = caveSys.dot(overlay)
+	case ".mmd":
+		output, err = caveSys.mermaid(overlay)
+	default:
+		return fmt.Errorf("unknown graph format %q", filepath.Ext(filename))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, []byte(output), 0644)
+}
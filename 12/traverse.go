@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// two big caves next to each other can be bounced between forever
+var ErrInfinitePaths = errors.New("big caves are connected, so there are infinite paths")
+
+// everything that decides where a path can go next
+type state struct {
+	cave *Cave
+	// small caves already on the path
+	visited uint64
+	// revisits still allowed
+	budget int
+}
+
+// where a path can go from here, and the state it'd be in after
+func (s state) next(nextCave *Cave) (state, bool) {
+	budget := s.budget
+
+	if s.visited&nextCave.bit != 0 {
+		if budget == 0 {
+			return state{}, false
+		}
+
+		// small cave we've been in before
+		budget--
+	}
+
+	return state{nextCave, s.visited | nextCave.bit, budget}, true
+}
+
+func (caveSys *CaveSystem) startState() state {
+	cave := caveSys.caves[start]
+
+	return state{cave, cave.bit, caveSys.revisits}
+}
+
+func (caveSys *CaveSystem) validate() error {
+	if caveSys.caves[start] == nil || caveSys.caves[end] == nil {
+		return errors.New("cave system needs a start and an end")
+	}
+
+	if caveSys.smallCaves > 64 {
+		return fmt.Errorf("%d small caves, but at most 64 are supported", caveSys.smallCaves)
+	}
+
+	if caveSys.revisits < 0 {
+		return errors.New("revisits can't be negative")
+	}
+
+	for _, cave := range caveSys.caves {
+		if !cave.isBig {
+			continue
+		}
+
+		for _, nextCave := range cave.flowsInto {
+			if nextCave.isBig {
+				return fmt.Errorf("%w: %s-%s", ErrInfinitePaths, cave.name, nextCave.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// number of paths from start to end, without building any of them:
+// paths from the same state all end the same way, so each state
+// is only counted once
+func (caveSys *CaveSystem) countPaths() (int, error) {
+	if err := caveSys.validate(); err != nil {
+		return 0, err
+	}
+
+	memo := map[state]int{}
+
+	var count func(s state) int
+
+	count = func(s state) int {
+		if s.cave.name == end {
+			return 1
+		}
+
+		if val, ok := memo[s]; ok {
+			return val
+		}
+
+		total := 0
+
+		for _, nextCave := range s.cave.flowsInto {
+			if next, ok := s.next(nextCave); ok {
+				total += count(next)
+			}
+		}
+
+		memo[s] = total
+
+		return total
+	}
+
+	return count(caveSys.startState()), nil
+}
+
+// a cave on the iterator's stack, and which of its connections is next
+type frame struct {
+	state
+	edge int
+}
+
+// PathIterator finds one path at a time, depth-first
+type PathIterator struct {
+	stack []frame
+	path  Path
+	// set if the cave system can't be traversed
+	err error
+}
+
+func (caveSys *CaveSystem) paths() *PathIterator {
+	it := &PathIterator{err: caveSys.validate()}
+
+	if it.err == nil {
+		s := caveSys.startState()
+		it.stack = []frame{{state: s}}
+		it.path = Path{s.cave}
+	}
+
+	return it
+}
+
+// the next path, or false when there are none left; the path
+// is reused by the iterator, so copy it to keep it
+func (it *PathIterator) next() (Path, bool) {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+
+		if top.edge == len(top.cave.flowsInto) {
+			// every way out of this cave has been tried
+			it.stack = it.stack[:len(it.stack)-1]
+			it.path = it.path[:len(it.path)-1]
+			continue
+		}
+
+		nextCave := top.cave.flowsInto[top.edge]
+		top.edge++
+
+		next, ok := top.next(nextCave)
+
+		if !ok {
+			continue
+		}
+
+		it.path = append(it.path, nextCave)
+
+		if nextCave.name == end {
+			found := it.path
+			// leave the stack as it was for the next call
+			it.path = it.path[:len(it.path)-1]
+
+			return found, true
+		}
+
+		it.stack = append(it.stack, frame{state: next})
+	}
+
+	return nil, false
+}
+
+// builds every path: fine for small cave systems
+func (caveSys *CaveSystem) findAllPaths() (Paths, error) {
+	it := caveSys.paths()
+	paths := Paths{}
+
+	for path, ok := it.next(); ok; path, ok = it.next() {
+		// append to an empty Path to copy it
+		paths = append(paths, append(Path{}, path...))
+	}
+
+	return paths, it.err
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
@@ -12,25 +13,51 @@ var FileLoader = utils.LoadAsLines
 func PartOne(content []string) (output int, err error) {
 	caveSys := newCaveSystem(content)
 
-	output = caveSys.findAllPaths()
-
-	return
+	return caveSys.countPaths()
 }
 
 func PartTwo(content []string) (output int, err error) {
 	caveSys := newCaveSystem(content)
 
-	caveSys.viewSingleSmallCaveTwice = true
+	// a single small cave can be visited twice
+	caveSys.revisits = 1
+
+	return caveSys.countPaths()
+}
+
+func exportGraph(data []string, filename, overlay string) {
+	caveSys := newCaveSystem(data)
 
-	output = caveSys.findAllPaths()
+	var path Path
 
-	return
+	if overlay != "" {
+		var err error
+
+		if path, err = caveSys.parsePath(overlay); err != nil {
+			fmt.Println("invalid -overlay", err)
+			return
+		}
+	}
+
+	if err := caveSys.export(filename, path); err != nil {
+		fmt.Println("failed to export", err)
+		return
+	}
+
+	fmt.Printf("Wrote %s \n", filename)
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	revisitsFlag := flag.Int("revisits", -1, "also count paths with this many small cave revisits")
+	printFlag := flag.Bool("print", false, "print every path (with -revisits)")
+	exportFlag := flag.String("export", "", "write the cave graph to a .dot, .gv or .mmd file")
+	overlayFlag := flag.String("overlay", "", "path to highlight in the export, like start,A,end")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -50,4 +77,34 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *exportFlag != "" {
+		exportGraph(data, *exportFlag, *overlayFlag)
+	}
+
+	if *revisitsFlag < 0 {
+		return
+	}
+
+	caveSys := newCaveSystem(data)
+	caveSys.revisits = *revisitsFlag
+
+	count, err := caveSys.countPaths()
+
+	if err != nil {
+		fmt.Println("failed to count paths", err)
+		return
+	}
+
+	fmt.Printf("Paths with %d revisits: %d \n", *revisitsFlag, count)
+
+	if !*printFlag {
+		return
+	}
+
+	it := caveSys.paths()
+
+	for path, ok := it.next(); ok; path, ok = it.next() {
+		fmt.Println(path.String())
+	}
 }
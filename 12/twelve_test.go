@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -51,3 +53,139 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+var largerExample = []string{
+	"dc-end",
+	"HN-start",
+	"start-kj",
+	"dc-start",
+	"dc-HN",
+	"LN-dc",
+	"HN-end",
+	"kj-sa",
+	"kj-HN",
+	"kj-dc",
+}
+
+func TestCountPaths(t *testing.T) {
+	// revisits -> paths
+	expected := []int{19, 103}
+
+	for revisits, want := range expected {
+		caveSys := newCaveSystem(largerExample)
+		caveSys.revisits = revisits
+
+		got, err := caveSys.countPaths()
+
+		if err != nil || got != want {
+			t.Logf("%d revisits: expected %d, got %d (%v)", revisits, want, got, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestIteratorMatchesCount(t *testing.T) {
+	for revisits := 0; revisits < 4; revisits++ {
+		caveSys := newCaveSystem(vals)
+		caveSys.revisits = revisits
+
+		count, _ := caveSys.countPaths()
+		paths, err := caveSys.findAllPaths()
+
+		if err != nil || len(paths) != count {
+			t.Logf("%d revisits: counted %d, but found %d paths (%v)", revisits, count, len(paths), err)
+			t.Fail()
+		}
+
+		unique := map[string]bool{}
+
+		for _, path := range paths {
+			unique[path.String()] = true
+
+			if path[0].name != start || path[len(path)-1].name != end {
+				t.Logf("bad path %s", path.String())
+				t.Fail()
+			}
+		}
+
+		if len(unique) != len(paths) {
+			t.Logf("%d revisits: found duplicate paths", revisits)
+			t.Fail()
+		}
+	}
+
+	caveSys := newCaveSystem(vals)
+	paths, _ := caveSys.findAllPaths()
+	expected := "start,A,b,A,c,A,end\nstart,A,b,A,end\nstart,A,b,end\nstart,A,c,A,b,A,end\nstart,A,c,A,b,end\nstart,A,c,A,end\nstart,A,end\nstart,b,A,c,A,end\nstart,b,A,end\nstart,b,end"
+
+	if paths.String() != expected {
+		t.Logf("expected paths:\n%s\ngot:\n%s", expected, paths.String())
+		t.Fail()
+	}
+}
+
+func TestInfinitePaths(t *testing.T) {
+	caveSys := newCaveSystem([]string{"start-A", "A-B", "B-end"})
+
+	if _, err := caveSys.countPaths(); !errors.Is(err, ErrInfinitePaths) {
+		t.Logf("expected ErrInfinitePaths, got %v", err)
+		t.Fail()
+	}
+
+	if _, ok := caveSys.paths().next(); ok {
+		t.Log("iterator shouldn't find any paths")
+		t.Fail()
+	}
+}
+
+func TestExportOverlay(t *testing.T) {
+	caveSys := newCaveSystem(vals)
+	path, err := caveSys.parsePath("start,A,b,A,end")
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+	}
+
+	dot, _ := caveSys.dot(path)
+
+	for _, line := range []string{
+		`"A" [shape=box, color=red, style=bold];`,
+		`"d" [shape=ellipse];`,
+		`"start" [shape=doublecircle, color=red, style=bold];`,
+		`"A" -> "b" [color=red, penwidth=2, label="2"];`,
+		`"b" -> "d";`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Logf("expected dot to have %s, got:\n%s", line, dot)
+			t.Fail()
+		}
+	}
+
+	// start only flows out, and end only flows in
+	if strings.Contains(dot, `-> "start"`) || strings.Contains(dot, `"end" ->`) {
+		t.Logf("unexpected edges in:\n%s", dot)
+		t.Fail()
+	}
+
+	mermaid, _ := caveSys.mermaid(path)
+
+	for _, line := range []string{
+		`c0["A"]`,
+		`c1(["b"])`,
+		`c5(("start"))`,
+		`c0 -->|2| c1`,
+		`class c0 big`,
+		`linkStyle 1,2,3,8 stroke:red`,
+	} {
+		if !strings.Contains(mermaid, line) {
+			t.Logf("expected mermaid to have %s, got:\n%s", line, mermaid)
+			t.Fail()
+		}
+	}
+
+	if _, err := caveSys.parsePath("start,c,end"); err == nil {
+		t.Log("expected an error for a path that isn't connected")
+		t.Fail()
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// pixels per dot, so the letters are readable
+const dotScale = 8
+
+// dots in reading order, so the svg doesn't change between runs
+func (sheet *Sheet) sortedDots() []Coords {
+	dots := make([]Coords, 0, len(sheet.dots))
+
+	for dot := range sheet.dots {
+		dots = append(dots, dot)
+	}
+
+	sort.Slice(dots, func(i, j int) bool {
+		if dots[i][1] != dots[j][1] {
+			return dots[i][1] < dots[j][1]
+		}
+
+		return dots[i][0] < dots[j][0]
+	})
+
+	return dots
+}
+
+// one square per dot, on a white sheet
+func (sheet *Sheet) svg() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		sheet.width*dotScale, sheet.height*dotScale, sheet.width, sheet.height)
+	fmt.Fprintf(&sb, `  <rect width="%d" height="%d" fill="white"/>`+"\n", sheet.width, sheet.height)
+
+	for _, dot := range sheet.sortedDots() {
+		fmt.Fprintf(&sb, `  <rect x="%d" y="%d" width="1" height="1" fill="black"/>`+"\n", dot[0], dot[1])
+	}
+
+	sb.WriteString("</svg>\n")
+
+	return sb.String()
+}
+
+func (sheet *Sheet) image() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, sheet.width*dotScale, sheet.height*dotScale))
+
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+
+	for dot := range sheet.dots {
+		for dy := 0; dy < dotScale; dy++ {
+			for dx := 0; dx < dotScale; dx++ {
+				img.SetGray(dot[0]*dotScale+dx, dot[1]*dotScale+dy, color.Gray{0})
+			}
+		}
+	}
+
+	return img
+}
+
+// .svg, or a .png or .pgm image
+func (sheet *Sheet) export(filename string) error {
+	if filepath.Ext(filename) == ".svg" {
+		return os.WriteFile(filename, []byte(sheet.svg()), 0644)
+	}
+
+	return utils.SaveImage(filename, sheet.image())
+}
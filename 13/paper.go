@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -13,144 +14,211 @@ type FoldInstruction struct {
 	line int
 }
 
+type Coords [2]int
+
 type Paper struct {
-	dots             map[int]map[int]bool
+	// every dot once, as it is before any folds
+	dots []Coords
+	// size of the unfolded paper
+	width, height    int
 	foldInstructions []FoldInstruction
 }
 
-func (paper *Paper) drawDot(x, y int) {
-	if paper.dots[x] == nil {
-		paper.dots[x] = map[int]bool{}
+func newPaper(data string) (*Paper, error) {
+	parts := utils.SplitByEmptyNewline(data)
+
+	if len(parts) != 2 {
+		return nil, errors.New("expected dots, a blank line, then fold instructions")
 	}
-	paper.dots[x][y] = true
-}
 
-func newPaper(data string) (paper Paper) {
-	parts := utils.SplitByEmptyNewline(data)
 	dotCoords, instructions := parts[0], parts[1]
+	paper := &Paper{}
+	seen := map[Coords]bool{}
 
-	paper.dots = map[int]map[int]bool{}
-
-	for _, coordStr := range strings.Split(dotCoords, "\n") {
+	for i, coordStr := range strings.Split(dotCoords, "\n") {
 		var x, y int
 
-		fmt.Sscanf(coordStr, "%d,%d", &x, &y)
+		if _, err := fmt.Sscanf(strings.TrimSpace(coordStr), "%d,%d", &x, &y); err != nil {
+			return nil, fmt.Errorf("dot %d: %q: %w", i+1, coordStr, err)
+		}
+
+		if x < 0 || y < 0 {
+			return nil, fmt.Errorf("dot %d: %q is off the paper", i+1, coordStr)
+		}
 
-		paper.drawDot(x, y)
+		if seen[Coords{x, y}] {
+			continue
+		}
+
+		seen[Coords{x, y}] = true
+		paper.dots = append(paper.dots, Coords{x, y})
+		paper.width = utils.MaxInt(paper.width, x+1)
+		paper.height = utils.MaxInt(paper.height, y+1)
 	}
 
-	for _, instructionStr := range strings.Split(instructions, "\n") {
+	for i, instructionStr := range strings.Split(instructions, "\n") {
 		var axis string
 		var line int
 
 		// lucky caught that this should be %1s
-		fmt.Sscanf(instructionStr, "fold along %1s=%d", &axis, &line)
+		if _, err := fmt.Sscanf(strings.TrimSpace(instructionStr), "fold along %1s=%d", &axis, &line); err != nil {
+			return nil, fmt.Errorf("fold %d: %q: %w", i+1, instructionStr, err)
+		}
+
+		if axis != "x" && axis != "y" {
+			return nil, fmt.Errorf("fold %d: unknown axis %q", i+1, axis)
+		}
 
 		paper.foldInstructions = append(paper.foldInstructions, FoldInstruction{
 			axis, line,
 		})
 	}
 
-	return
-}
-
-type Coords [2]int
+	// the paper is big enough for every dot, and the first fold
+	// along each axis is in the middle
+	for _, axis := range []string{"x", "y"} {
+		for _, instruction := range paper.foldInstructions {
+			if instruction.axis != axis {
+				continue
+			}
 
-func (paper *Paper) foldFunc(fn func(x, y int) Coords) {
-	nextDots := []Coords{}
+			if axis == "x" {
+				paper.width = utils.MaxInt(paper.width, 2*instruction.line+1)
+			} else {
+				paper.height = utils.MaxInt(paper.height, 2*instruction.line+1)
+			}
 
-	for x, row := range paper.dots {
-		for y := range row {
-			nextDots = append(nextDots, fn(x, y))
+			break
 		}
 	}
 
-	// brand new board
-	paper.dots = map[int]map[int]bool{}
+	return paper, nil
+}
+
+// Transform is any number of folds at once: folds along x never
+// change y (and vice versa), so each axis is just a lookup table
+type Transform struct {
+	// old coordinate -> folded coordinate; -1 if it was on a fold line
+	xs, ys []int
+}
 
-	for _, coords := range nextDots {
-		x, y := coords[0], coords[1]
+func identity(size int) []int {
+	table := make([]int, size)
 
-		paper.drawDot(x, y)
+	for i := range table {
+		table[i] = i
 	}
+
+	return table
 }
 
-func (paper *Paper) foldUp(lineNum int) {
-	paper.foldFunc(func(x, y int) Coords {
-		if y > lineNum {
-			diff := y - lineNum
-			y = lineNum - diff
-		}
+// folds the table over the line; returns an error if the fold
+// doesn't fit the paper
+func foldTable(table []int, size, line int) error {
+	if line <= 0 || line >= size {
+		return fmt.Errorf("fold at %d is past the edge of the paper (size %d)", line, size)
+	}
 
-		return Coords{x, y}
-	})
-}
+	// the folded part has to land on the paper
+	if size-1 > 2*line {
+		return fmt.Errorf("fold at %d is too short: the paper (size %d) would hang off the other edge", line, size)
+	}
 
-func (paper *Paper) foldLeft(lineNum int) {
-	paper.foldFunc(func(x, y int) Coords {
-		if x > lineNum {
-			diff := x - lineNum
-			x = lineNum - diff
+	for i, val := range table {
+		if val == line {
+			table[i] = -1
+		} else if val > line {
+			table[i] = 2*line - val
 		}
+	}
 
-		return Coords{x, y}
-	})
+	return nil
 }
 
-func (paper *Paper) fold(instruction FoldInstruction) {
-	if instruction.axis == "y" {
-		paper.foldUp(instruction.line)
-	} else {
-		paper.foldLeft(instruction.line)
+// collapses the first `steps` folds into a single Transform, and the
+// size of the paper after them
+func (paper *Paper) transform(steps int) (transform Transform, width, height int, err error) {
+	if steps < 0 || steps > len(paper.foldInstructions) {
+		err = fmt.Errorf("step %d is out of range 0-%d", steps, len(paper.foldInstructions))
+		return
 	}
-}
 
-func (paper *Paper) countDots() (count int) {
-	for _, row := range paper.dots {
-		count += len(row)
+	width, height = paper.width, paper.height
+	transform = Transform{identity(width), identity(height)}
+
+	for i, instruction := range paper.foldInstructions[:steps] {
+		if instruction.axis == "y" {
+			err = foldTable(transform.ys, height, instruction.line)
+			height = instruction.line
+		} else {
+			err = foldTable(transform.xs, width, instruction.line)
+			width = instruction.line
+		}
+
+		if err != nil {
+			err = fmt.Errorf("fold %d: %w", i+1, err)
+			return
+		}
 	}
 
 	return
 }
 
-// output a board similar to adventofcode.com/2021/day/13
-func (paper *Paper) Board() (output string) {
-	width := 0
-	height := 0
-
-	for x, row := range paper.dots {
-		for y := range row {
-			// 0-indexed
-			x++
-			y++
-
-			if x > width {
-				width = x
-			}
+func (transform Transform) apply(coords Coords) Coords {
+	return Coords{transform.xs[coords[0]], transform.ys[coords[1]]}
+}
 
-			if y > height {
-				height = y
-			}
-		}
-	}
+// Sheet is the paper after some folds
+type Sheet struct {
+	width, height int
+	dots          map[Coords]bool
+}
 
-	board := make([][]string, height)
+// the paper after the first `steps` folds; every dot is moved once
+func (paper *Paper) fold(steps int) (*Sheet, error) {
+	transform, width, height, err := paper.transform(steps)
 
-	for r := range board {
-		board[r] = strings.Split(strings.Repeat(".", width), "")
+	if err != nil {
+		return nil, err
 	}
 
-	for x, row := range paper.dots {
-		for y := range row {
-			board[y][x] = "#"
+	sheet := &Sheet{width, height, map[Coords]bool{}}
+
+	for _, dot := range paper.dots {
+		folded := transform.apply(dot)
+
+		// dots never appear on a fold line
+		if folded[0] < 0 || folded[1] < 0 {
+			return nil, fmt.Errorf("dot %v is on a fold line", dot)
 		}
+
+		sheet.dots[folded] = true
 	}
 
-	output += "\n\n"
+	return sheet, nil
+}
 
-	for _, row := range board {
-		output += strings.Join(row, "") + "\n"
+func (sheet *Sheet) countDots() int {
+	return len(sheet.dots)
+}
+
+// output a board similar to adventofcode.com/2021/day/13
+func (sheet *Sheet) Board() string {
+	var sb strings.Builder
+
+	sb.WriteString("\n\n")
+
+	for y := 0; y < sheet.height; y++ {
+		for x := 0; x < sheet.width; x++ {
+			if sheet.dots[Coords{x, y}] {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+
+		sb.WriteByte('\n')
 	}
 
-	return
+	return sb.String()
 }
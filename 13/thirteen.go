@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -21,29 +22,42 @@ func init() {
 func PartOne(content string) (output int, err error) {
 	log.Println("-- Part One --")
 
-	paper := newPaper(content)
+	paper, err := newPaper(content)
 
-	log.Println(paper.Board())
+	if err != nil {
+		return
+	}
+
+	var sheet *Sheet
+
+	for steps := 0; steps <= 1; steps++ {
+		if sheet, err = paper.fold(steps); err != nil {
+			return
+		}
 
-	for _, instruction := range paper.foldInstructions[0:1] {
-		paper.fold(instruction)
-		log.Println(paper.Board())
+		log.Println(sheet.Board())
 	}
 
-	return paper.countDots(), nil
+	return sheet.countDots(), nil
 }
 
 // the folded paper reveals eight capital letters
 func PartTwo(content string) (output int, err error) {
-	paper := newPaper(content)
+	paper, err := newPaper(content)
 
-	for _, instruction := range paper.foldInstructions {
-		paper.fold(instruction)
+	if err != nil {
+		return
+	}
+
+	sheet, err := paper.fold(len(paper.foldInstructions))
+
+	if err != nil {
+		return
 	}
 
 	// today I decided to suppress logs at runtime
 	// today the puzzle demands I output some ascii art
-	fmt.Println(paper.Board())
+	fmt.Println(sheet.Board())
 
 	return
 }
@@ -53,6 +67,11 @@ func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	exportFlag := flag.String("export", "", "write the paper to a .svg, .png or .pgm file")
+	stepFlag := flag.Int("step", -1, "how many folds to export (defaults to all of them)")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -72,4 +91,34 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *exportFlag == "" {
+		return
+	}
+
+	paper, err := newPaper(data)
+
+	if err != nil {
+		fmt.Println("failed to load paper", err)
+		return
+	}
+
+	steps := *stepFlag
+
+	if steps < 0 {
+		steps = len(paper.foldInstructions)
+	}
+
+	sheet, err := paper.fold(steps)
+
+	if err == nil {
+		err = sheet.export(*exportFlag)
+	}
+
+	if err != nil {
+		fmt.Println("failed to export", err)
+		return
+	}
+
+	fmt.Printf("Wrote %s after %d folds \n", *exportFlag, steps)
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -59,3 +60,82 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestFoldValidation(t *testing.T) {
+	tests := map[string]string{
+		"unknown axis":    "0,0\n4,4\n\nfold along z=2",
+		"past the edge":   "0,0\n4,4\n\nfold along x=2\nfold along x=3",
+		"too short":       "0,0\n8,0\n\nfold along x=2",
+		"on a fold line":  "0,0\n2,0\n\nfold along x=2",
+		"no instructions": "0,0\n4,4",
+	}
+
+	for name, data := range tests {
+		paper, err := newPaper(data)
+
+		if err == nil {
+			_, err = paper.fold(len(paper.foldInstructions))
+		}
+
+		if err == nil {
+			t.Logf("%s: expected an error", name)
+			t.Fail()
+		}
+	}
+}
+
+func TestFoldSteps(t *testing.T) {
+	paper, _ := newPaper(vals)
+
+	// size and dots after each step
+	expected := []Sheet{
+		{width: 11, height: 15},
+		{width: 11, height: 7},
+		{width: 5, height: 7},
+	}
+	dots := []int{18, 17, 16}
+
+	for steps, want := range expected {
+		sheet, err := paper.fold(steps)
+
+		if err != nil {
+			t.Log("error should be nil", err)
+			t.Fail()
+			continue
+		}
+
+		if sheet.width != want.width || sheet.height != want.height || sheet.countDots() != dots[steps] {
+			t.Logf("step %d: expected %dx%d with %d dots, got %dx%d with %d", steps, want.width, want.height, dots[steps], sheet.width, sheet.height, sheet.countDots())
+			t.Fail()
+		}
+	}
+
+	if _, err := paper.fold(3); err == nil {
+		t.Log("expected an error for a step past the last fold")
+		t.Fail()
+	}
+}
+
+func TestExport(t *testing.T) {
+	paper, _ := newPaper(vals)
+	sheet, _ := paper.fold(2)
+
+	svg := sheet.svg()
+
+	if count := strings.Count(svg, `fill="black"`); count != 16 {
+		t.Logf("expected 16 dots in the svg, got %d", count)
+		t.Fail()
+	}
+
+	if !strings.Contains(svg, `viewBox="0 0 5 7"`) {
+		t.Logf("unexpected svg size: %s", svg)
+		t.Fail()
+	}
+
+	bounds := sheet.image().Bounds()
+
+	if bounds.Dx() != 5*dotScale || bounds.Dy() != 7*dotScale {
+		t.Logf("unexpected image size %v", bounds)
+		t.Fail()
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Expander streams the actual polymer after some steps, without
+// building it: every pair grows into a tree of insertions, and
+// only the parts of the trees that are asked for are walked
+type Expander struct {
+	polymer *Polymer
+	steps   int
+	// how many elements a pair grows between its two ends
+	memo map[expansionKey]int
+}
+
+type expansionKey struct {
+	pair  string
+	depth int
+}
+
+func newExpander(polymer *Polymer, steps int) *Expander {
+	return &Expander{
+		polymer: polymer,
+		steps:   steps,
+		memo:    map[expansionKey]int{},
+	}
+}
+
+// lengths grow exponentially, so they stop at MaxInt rather than
+// overflow; nothing past that can be asked for anyway
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+
+	return a + b
+}
+
+// elements inserted between the pair's two ends after depth steps
+func (expander *Expander) inner(pair string, depth int) int {
+	replacement, ok := expander.polymer.insertionRules[pair]
+
+	if depth == 0 || !ok {
+		return 0
+	}
+
+	key := expansionKey{pair, depth}
+
+	if val, ok := expander.memo[key]; ok {
+		return val
+	}
+
+	left := expander.inner(pair[0:1]+replacement, depth-1)
+	right := expander.inner(replacement+pair[1:2], depth-1)
+	val := saturatingAdd(saturatingAdd(left, 1), right)
+
+	expander.memo[key] = val
+
+	return val
+}
+
+// length of the polymer (or MaxInt, if it's longer)
+func (expander *Expander) length() int {
+	template := expander.polymer.template
+	length := len(template)
+
+	for i := 0; i < len(template)-1; i++ {
+		length = saturatingAdd(length, expander.inner(template[i:i+2], expander.steps))
+	}
+
+	return length
+}
+
+// recursive generator: calls fn with every element inserted in the
+// pair that's in [from, to); offset is where the first one would be.
+// returns false once fn wants to stop
+func (expander *Expander) walk(pair string, depth, offset, from, to int, fn func(pos int, char byte) bool) bool {
+	size := expander.inner(pair, depth)
+
+	if size == 0 || offset >= to || saturatingAdd(offset, size) <= from {
+		return true
+	}
+
+	replacement := expander.polymer.insertionRules[pair]
+	left := pair[0:1] + replacement
+	leftSize := expander.inner(left, depth-1)
+	middle := saturatingAdd(offset, leftSize)
+
+	if !expander.walk(left, depth-1, offset, from, to, fn) {
+		return false
+	}
+
+	if middle >= from && middle < to && !fn(middle, replacement[0]) {
+		return false
+	}
+
+	return expander.walk(replacement+pair[1:2], depth-1, saturatingAdd(middle, 1), from, to, fn)
+}
+
+// calls fn with every element of the polymer in [from, to), in order,
+// until fn returns false
+func (expander *Expander) each(from, to int, fn func(pos int, char byte) bool) error {
+	length := expander.length()
+
+	if from < 0 || to > length || from > to {
+		return fmt.Errorf("range [%d, %d) is outside the polymer (length %d)", from, to, length)
+	}
+
+	template := expander.polymer.template
+	pos := 0
+
+	for i := 0; i < len(template) && pos < to; i++ {
+		if pos >= from && !fn(pos, template[i]) {
+			return nil
+		}
+
+		pos++
+
+		if i == len(template)-1 {
+			break
+		}
+
+		pair := template[i : i+2]
+
+		if !expander.walk(pair, expander.steps, pos, from, to, fn) {
+			return nil
+		}
+
+		pos = saturatingAdd(pos, expander.inner(pair, expander.steps))
+	}
+
+	return nil
+}
+
+// the polymer from `from` up to (not including) `to`
+func (expander *Expander) substring(from, to int) (string, error) {
+	var sb strings.Builder
+
+	err := expander.each(from, to, func(pos int, char byte) bool {
+		sb.WriteByte(char)
+
+		return true
+	})
+
+	return sb.String(), err
+}
+
+// the nth element (0-indexed): only walks one path down the trees
+func (expander *Expander) charAt(n int) (byte, error) {
+	str, err := expander.substring(n, n+1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return str[0], nil
+}
+
+// counts every element and pair by streaming the whole polymer,
+// to check against the pair-count model
+func (expander *Expander) counts() Elements {
+	elements := newElements("")
+	one := big.NewInt(1)
+	previous := ""
+
+	expander.each(0, expander.length(), func(pos int, char byte) bool {
+		add(elements.charCount, string(char), one)
+
+		if previous != "" {
+			add(elements.pairs, previous+string(char), one)
+		}
+
+		previous = string(char)
+
+		return true
+	})
+
+	return elements
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/big"
+	"os"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
 )
@@ -22,39 +25,57 @@ func init() {
 func PartOne(content string) (output int, err error) {
 	log.Println("-- PART ONE --")
 
-	polymer := newPolymer(content)
+	polymer, err := newPolymer(content)
 
-	elements := polymer.pairInsertion(10)
-
-	log.Println("\n", polymer.template, newElements(polymer.template))
+	if err != nil {
+		return
+	}
 
-	// first step
-	log.Println("\n", "NCNBCHB\n", polymer.pairInsertion(1))
+	var elements Elements
 
-	// 10th step
-	log.Println("\n", "Step 10", "\n", elements)
+	polymer.each(func(step int, current Elements) bool {
+		log.Println("Step", step, current)
+		elements = current
 
-	min, max := elements.getMinMax()
+		return step < 10
+	})
 
-	return max - min, nil
+	return toInt(elements.spread())
 }
 
 func PartTwo(content string) (output int, err error) {
 	log.Println("-- PART TWO --")
 
-	polymer := newPolymer(content)
+	polymer, err := newPolymer(content)
+
+	if err != nil {
+		return
+	}
 
 	elements := polymer.pairInsertion(40)
 
-	min, max := elements.getMinMax()
+	return toInt(elements.spread())
+}
+
+func toInt(num *big.Int) (int, error) {
+	if !num.IsInt64() {
+		return -1, fmt.Errorf("%s is too big for an int", num)
+	}
 
-	return max - min, nil
+	return int(num.Int64()), nil
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	stepsFlag := flag.Uint64("steps", 0, "also print the counts after this many steps")
+	csvFlag := flag.String("csv", "", "write counts for every step (up to -steps) to a csv file")
+	atFlag := flag.Int("at", -1, "print the element at this (0-indexed) position after -steps")
+	rangeFlag := flag.Int("range", 1, "how many elements to print with -at")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -74,4 +95,57 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *stepsFlag == 0 {
+		return
+	}
+
+	polymer, err := newPolymer(data)
+
+	if err != nil {
+		fmt.Println("failed to load polymer", err)
+		return
+	}
+
+	elements := polymer.elementsAfter(*stepsFlag)
+
+	fmt.Printf("Step %d: %s \n", *stepsFlag, elements)
+	fmt.Printf("Most minus least common: %s \n", elements.spread())
+
+	if *atFlag >= 0 {
+		expander := newExpander(polymer, int(*stepsFlag))
+		str, err := expander.substring(*atFlag, *atFlag+*rangeFlag)
+
+		if err != nil {
+			fmt.Println("failed to expand polymer", err)
+			return
+		}
+
+		fmt.Printf("Elements %d-%d: %s \n", *atFlag, *atFlag+*rangeFlag-1, str)
+	}
+
+	if *csvFlag == "" {
+		return
+	}
+
+	if err := writeGrowth(polymer, *csvFlag, int(*stepsFlag)); err != nil {
+		fmt.Println("failed to write csv", err)
+	}
+}
+
+func writeGrowth(polymer *Polymer, filename string, steps int) error {
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return err
+	}
+
+	err = polymer.writeCSV(file, steps)
+
+	// a failed close can mean the rows never made it to disk
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
 }
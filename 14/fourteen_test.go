@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"log"
+	"math/big"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -58,3 +62,159 @@ func TestExampleTwo(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestStepCounts(t *testing.T) {
+	polymer, _ := newPolymer(vals)
+
+	// NCNBCHB
+	expected := "{B: 2, C: 2, H: 1, N: 2}"
+
+	if got := polymer.pairInsertion(1).String(); got != expected {
+		t.Logf("expected %s, got %s", expected, got)
+		t.Fail()
+	}
+}
+
+func TestMatrixMatchesSteps(t *testing.T) {
+	polymer, _ := newPolymer(vals)
+
+	polymer.each(func(step int, elements Elements) bool {
+		matrix := polymer.elementsAfter(uint64(step))
+
+		if matrix.String() != elements.String() {
+			t.Logf("step %d: expected %s, got %s", step, elements, matrix)
+			t.Fail()
+		}
+
+		return step < 100
+	})
+
+	// too big for an int64
+	if polymer.elementsAfter(100).spread().IsInt64() {
+		t.Log("expected step 100 to overflow int64")
+		t.Fail()
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	polymer, _ := newPolymer(vals)
+
+	var buf bytes.Buffer
+
+	if err := polymer.writeCSV(&buf, 2); err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+	}
+
+	expected := "step,B,C,H,N\n0,1,1,0,2\n1,2,2,1,2\n2,6,4,1,2\n"
+
+	if buf.String() != expected {
+		t.Logf("expected %q, got %q", expected, buf.String())
+		t.Fail()
+	}
+
+	if _, err := newPolymer("NN\n\nNN => C"); err == nil || !strings.Contains(err.Error(), "rule 1") {
+		t.Logf("expected a rule error, got %v", err)
+		t.Fail()
+	}
+}
+
+func TestExpanderSteps(t *testing.T) {
+	polymer, _ := newPolymer(vals)
+
+	expected := []string{
+		"NNCB",
+		"NCNBCHB",
+		"NBCCNBBBCBHCB",
+		"NBBBCNCCNBBNBNBBCHBHHBCHB",
+		"NBBNBNBBCCNBCNCCNBBNBBNBBBNBBNBBCBHCBHHNHCBBCBHCB",
+	}
+
+	for steps, want := range expected {
+		expander := newExpander(polymer, steps)
+		got, err := expander.substring(0, expander.length())
+
+		if err != nil || got != want {
+			t.Logf("step %d: expected %s, got %s (%v)", steps, want, got, err)
+			t.Fail()
+		}
+	}
+
+	expander := newExpander(polymer, 4)
+
+	if got, _ := expander.substring(10, 15); got != expected[4][10:15] {
+		t.Logf("expected %s, got %s", expected[4][10:15], got)
+		t.Fail()
+	}
+
+	if _, err := expander.substring(40, 60); err == nil {
+		t.Log("expected an error past the end of the polymer")
+		t.Fail()
+	}
+}
+
+// counts as strings, leaving out zeros, so maps can be compared whole
+func nonZeroCounts(counts map[string]*big.Int) map[string]string {
+	out := map[string]string{}
+
+	for key, count := range counts {
+		if count.Sign() != 0 {
+			out[key] = count.String()
+		}
+	}
+
+	return out
+}
+
+func TestExpanderMatchesCounts(t *testing.T) {
+	polymer, _ := newPolymer(vals)
+
+	polymer.each(func(step int, elements Elements) bool {
+		streamed := newExpander(polymer, step).counts()
+
+		for _, counts := range [][2]map[string]*big.Int{
+			{elements.charCount, streamed.charCount},
+			{elements.pairs, streamed.pairs},
+		} {
+			expected, got := nonZeroCounts(counts[0]), nonZeroCounts(counts[1])
+
+			if !reflect.DeepEqual(expected, got) {
+				t.Logf("step %d: counts should be %v, got %v", step, expected, got)
+				t.Fail()
+			}
+		}
+
+		return step < 10
+	})
+
+	// every pair has a rule, so the length doubles (less one) each step
+	expander := newExpander(polymer, 40)
+	length := 3<<40 + 1
+
+	if expander.length() != length {
+		t.Logf("expected length %d, got %d", length, expander.length())
+		t.Fail()
+	}
+
+	// random access agrees with streaming
+	step10 := newExpander(polymer, 10)
+	full, _ := step10.substring(0, step10.length())
+
+	for n := range full {
+		if char, _ := step10.charAt(n); char != full[n] {
+			t.Logf("element %d: expected %c, got %c", n, full[n], char)
+			t.Fail()
+			break
+		}
+	}
+
+	if first, _ := expander.charAt(0); first != 'N' {
+		t.Logf("expected the first element to be N, got %c", first)
+		t.Fail()
+	}
+
+	if last, _ := expander.charAt(length - 1); last != 'B' {
+		t.Logf("expected the last element to be B, got %c", last)
+		t.Fail()
+	}
+}
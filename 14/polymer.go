@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
-	"log"
-	"math"
+	"io"
+	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
@@ -17,126 +20,295 @@ type Polymer struct {
 	insertionRules map[string]string
 }
 
-func newPolymer(data string) *Polymer {
+func newPolymer(data string) (*Polymer, error) {
 	polymer := &Polymer{
 		insertionRules: map[string]string{},
 	}
 
 	parts := utils.SplitByEmptyNewline(data)
 
-	polymer.template = parts[0]
+	if len(parts) != 2 {
+		return nil, errors.New("expected a template, a blank line, then insertion rules")
+	}
+
+	polymer.template = strings.TrimSpace(parts[0])
+
+	if polymer.template == "" {
+		return nil, errors.New("template is empty")
+	}
 
-	for _, instruction := range strings.Split(parts[1], "\n") {
+	for i, instruction := range strings.Split(parts[1], "\n") {
 		var key, val string
 
 		// for griff
-		if count, err := fmt.Sscanf(instruction, "%2s -> %1s", &key, &val); count != 2 || err != nil {
-			if err != nil {
-				log.Println("failed to parse instruction", instruction)
-				log.Println(err)
-				// maybe EOF?
-				continue
-			}
-			panic("could not initialize all values of " + instruction)
+		if count, err := fmt.Sscanf(strings.TrimSpace(instruction), "%2s -> %1s", &key, &val); count != 2 || err != nil {
+			return nil, fmt.Errorf("rule %d: could not parse %q", i+1, instruction)
+		}
+
+		if len(key) != 2 {
+			return nil, fmt.Errorf("rule %d: %q is not a pair", i+1, key)
 		}
 
 		polymer.insertionRules[key] = val
 	}
 
-	return polymer
+	return polymer, nil
 }
 
 type Elements struct {
-	pairs, charCount map[string]int
+	pairs, charCount map[string]*big.Int
 }
 
-func (elements *Elements) merge(otherElements Elements) {
-	for key, val := range otherElements.pairs {
-		elements.pairs[key] += val
+// adds n to m[key]
+func add(m map[string]*big.Int, key string, n *big.Int) {
+	if m[key] == nil {
+		m[key] = new(big.Int)
 	}
 
-	for key, val := range otherElements.charCount {
-		elements.charCount[key] += val
-	}
+	m[key].Add(m[key], n)
 }
 
 func newElements(template string) Elements {
 	elements := Elements{
-		pairs:     map[string]int{},
-		charCount: map[string]int{},
+		pairs:     map[string]*big.Int{},
+		charCount: map[string]*big.Int{},
 	}
 
+	one := big.NewInt(1)
+
 	for i := 0; i < len(template); i++ {
 		if i < len(template)-1 {
 			pair := template[i : i+2]
-			elements.pairs[pair]++
+			add(elements.pairs, pair, one)
 		}
 
 		char := template[i : i+1]
-		elements.charCount[char]++
+		add(elements.charCount, char, one)
 	}
 
 	return elements
 }
 
-func (polymer *Polymer) pairInsertion(steps int) Elements {
+// one step: every pair with a rule becomes two pairs; returns
+// brand new Elements, so older steps stay as they were
+func (polymer *Polymer) insert(elements Elements) Elements {
+	nextElements := newElements("")
+
+	for char, count := range elements.charCount {
+		add(nextElements.charCount, char, count)
+	}
+
+	for ref, count := range elements.pairs {
+		replacement, ok := polymer.insertionRules[ref]
+
+		if !ok {
+			// unmatched pairs persist
+			add(nextElements.pairs, ref, count)
+			continue
+		}
+
+		// increment char count for replacement
+		add(nextElements.charCount, replacement, count)
+
+		// the replacement creates two new pairs
+		// NN -> C creates NC & CN
+		// * NOTE: ref[0] is a byte, ref[0:1] is a string
+		add(nextElements.pairs, ref[0:1]+replacement, count)
+		add(nextElements.pairs, replacement+ref[1:2], count)
+	}
+
+	return nextElements
+}
+
+// calls fn with the counts at every step, starting with the template
+// (step 0), until fn returns false
+func (polymer *Polymer) each(fn func(step int, elements Elements) bool) {
 	elements := newElements(polymer.template)
 
-	// decrement steps
-	for ; steps > 0; steps-- {
-		// get empty Elements
-		nextElements := newElements("")
+	for step := 0; fn(step, elements); step++ {
+		elements = polymer.insert(elements)
+	}
+}
+
+func (polymer *Polymer) pairInsertion(steps int) (elements Elements) {
+	polymer.each(func(step int, current Elements) bool {
+		elements = current
 
-		// ? iterating each instruction, but maybe it would be
-		// better to iterate each elements.pairs instead
-		// (since elements.pairs is likely to be smaller)
-		for ref, replacement := range polymer.insertionRules {
-			// could check count > 0, but why waste a decent "ok"?
-			count, ok := elements.pairs[ref]
+		return step < steps
+	})
 
-			if !ok {
-				// it's ok not to be ok
-				continue
-			}
+	return
+}
+
+// every element in the template or the rules, sorted
+func (polymer *Polymer) alphabet() []string {
+	seen := map[string]bool{}
+
+	for _, char := range strings.Split(polymer.template, "") {
+		seen[char] = true
+	}
+
+	for ref, replacement := range polymer.insertionRules {
+		seen[ref[0:1]] = true
+		seen[ref[1:2]] = true
+		seen[replacement] = true
+	}
+
+	chars := []string{}
+
+	for char := range seen {
+		chars = append(chars, char)
+	}
+
+	sort.Strings(chars)
+
+	return chars
+}
+
+// counts after any number of steps, without doing each one: pair
+// counts are a vector, and a step is a matrix over every possible pair
+func (polymer *Polymer) elementsAfter(steps uint64) Elements {
+	chars := polymer.alphabet()
+	pairs := []string{}
+	index := map[string]int{}
+
+	for _, a := range chars {
+		for _, b := range chars {
+			index[a+b] = len(pairs)
+			pairs = append(pairs, a+b)
+		}
+	}
+
+	transition := utils.NewMatrix(len(pairs))
+
+	for from, pair := range pairs {
+		replacement, ok := polymer.insertionRules[pair]
+
+		if !ok {
+			transition[from][from].SetInt64(1)
+			continue
+		}
 
-			// increment char count for replacement
-			nextElements.charCount[replacement] += count
+		// add, since both new pairs can be the same (NN -> N)
+		left, right := index[pair[0:1]+replacement], index[replacement+pair[1:2]]
+		transition[left][from].Add(transition[left][from], big.NewInt(1))
+		transition[right][from].Add(transition[right][from], big.NewInt(1))
+	}
 
-			// the replacement creates two new pairs
-			// NN -> C creates NC & CN
-			// * NOTE: ref[0] is a byte, ref[0:1] is a string
-			firstNewPair := ref[0:1] + replacement
-			secondNewPair := replacement + ref[1:2]
+	start := newElements(polymer.template)
+	vec := make([]*big.Int, len(pairs))
 
-			nextElements.pairs[firstNewPair] += count
-			nextElements.pairs[secondNewPair] += count
+	for i, pair := range pairs {
+		vec[i] = new(big.Int)
 
-			// remove pair from pairs so that we can merge
-			// any unmatched pairs (they should persist)
-			delete(elements.pairs, ref)
+		if count, ok := start.pairs[pair]; ok {
+			vec[i].Set(count)
 		}
+	}
 
-		// merge any unmatched pairs
-		elements.merge(nextElements)
+	vec = transition.Power(steps, nil).Apply(vec, nil)
+
+	elements := newElements("")
+
+	for i, count := range vec {
+		if count.Sign() == 0 {
+			continue
+		}
+
+		add(elements.pairs, pairs[i], count)
+		// every element starts exactly one pair...
+		add(elements.charCount, pairs[i][0:1], count)
 	}
 
+	// ...except the last one, which never changes
+	last := polymer.template[len(polymer.template)-1:]
+	add(elements.charCount, last, big.NewInt(1))
+
 	return elements
 }
 
-func (elements *Elements) getMinMax() (int, int) {
-	// first time using Inf
-	max := math.Inf(-1)
-	min := math.Inf(1)
-
+func (elements Elements) getMinMax() (min, max *big.Int) {
 	for _, count := range elements.charCount {
-		floated := float64(count)
-		if floated > max {
-			max = floated
+		if count.Sign() == 0 {
+			continue
+		}
+
+		if max == nil || count.Cmp(max) > 0 {
+			max = count
 		}
-		if floated < min {
-			min = floated
+
+		if min == nil || count.Cmp(min) < 0 {
+			min = count
+		}
+	}
+
+	if min == nil {
+		return new(big.Int), new(big.Int)
+	}
+
+	return
+}
+
+// most common element minus least common element
+func (elements Elements) spread() *big.Int {
+	min, max := elements.getMinMax()
+
+	return new(big.Int).Sub(max, min)
+}
+
+// custom string representation: elements and counts, sorted
+func (elements Elements) String() string {
+	chars := []string{}
+
+	for char := range elements.charCount {
+		chars = append(chars, char)
+	}
+
+	sort.Strings(chars)
+
+	counts := make([]string, len(chars))
+
+	for i, char := range chars {
+		counts[i] = fmt.Sprintf("%s: %s", char, elements.charCount[char])
+	}
+
+	return "{" + strings.Join(counts, ", ") + "}"
+}
+
+// one row per step, one column per element
+func (polymer *Polymer) writeCSV(writer io.Writer, steps int) error {
+	w := csv.NewWriter(writer)
+	chars := polymer.alphabet()
+
+	if err := w.Write(append([]string{"step"}, chars...)); err != nil {
+		return err
+	}
+
+	var err error
+
+	polymer.each(func(step int, elements Elements) bool {
+		record := []string{fmt.Sprint(step)}
+
+		for _, char := range chars {
+			count, ok := elements.charCount[char]
+
+			if !ok {
+				count = new(big.Int)
+			}
+
+			record = append(record, count.String())
 		}
+
+		err = w.Write(record)
+
+		return err == nil && step < steps
+	})
+
+	if err != nil {
+		return err
 	}
 
-	return int(min), int(max)
+	w.Flush()
+
+	return w.Error()
 }
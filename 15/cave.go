@@ -1,150 +1,112 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 
-	"github.com/bozdoz/advent-of-code-2021/types"
 	"github.com/bozdoz/advent-of-code-2021/utils"
 )
 
-type Cell struct {
-	// risk factor
-	value int
-	// tentative distance (Dijkstra's algorithm)
-	distance int
-	// we're not coming back
-	visited    bool
-	neighbours []*Cell
+// anything bigger won't fit in memory to search
+const maxCells = 1 << 30
+
+type Point struct {
+	row, col int
 }
 
-type Row []*Cell
-type Grid []Row
+// up, right, down, left
+var directions = [4]Point{{-1, 0}, {0, 1}, {1, 0}, {0, -1}}
 
+// Cave is one tile of risks, repeated `multiplier` times in each
+// direction; the repeats are never stored, just worked out when needed
 type Cave struct {
-	grid          Grid
-	height, width int
-	start, end    *Cell
+	tile               [][]int
+	tileRows, tileCols int
+	multiplier         int
+	height, width      int
 }
 
-func newCave(data []string, multiplier int) (cave Cave) {
-	rows := len(data)
-	cols := len(data[0])
-	cave.height = rows * multiplier
-	cave.width = cols * multiplier
-	// math.Inf is awful to work with
-	startingDistance := cave.height * cave.width * 10
-
-	// make all rows
-	for range data {
-		for i := 1; i <= multiplier; i++ {
-			cave.grid = append(cave.grid, make(Row, cave.width))
-		}
+func newCave(data []string, multiplier int) (*Cave, error) {
+	if multiplier < 1 {
+		return nil, errors.New("multiplier has to be at least 1")
 	}
 
-	for r, line := range data {
-		for i := 0; i < multiplier; i++ {
-			for c, char := range strings.Split(line, "") {
-				value, err := strconv.Atoi(char)
-
-				if err != nil {
-					panic("could not convert char to int")
-				}
-
-				for j := 0; j < multiplier; j++ {
-					adjRow := rows*i + r
-					adjCol := cols*j + c
-					newVal := value + i + j
-
-					if newVal > 9 {
-						newVal = newVal - 9
-					}
-
-					cave.grid[adjRow][adjCol] = &Cell{
-						value:    newVal,
-						distance: startingDistance,
-					}
-				}
-			}
-		}
+	// ignore trailing blank lines
+	for len(data) > 0 && data[len(data)-1] == "" {
+		data = data[:len(data)-1]
 	}
 
-	cave.start = cave.grid[0][0]
-	// start is 0 distance away from start
-	cave.start.distance = 0
-	cave.end = cave.grid[cave.height-1][cave.width-1]
+	if len(data) == 0 {
+		return nil, errors.New("cave is empty")
+	}
 
-	cave.updateNeighbours()
+	cave := &Cave{
+		tileRows:   len(data),
+		tileCols:   len(data[0]),
+		multiplier: multiplier,
+	}
 
-	return
-}
+	for r, line := range data {
+		if len(line) != cave.tileCols {
+			return nil, fmt.Errorf("row %d has %d cells, expected %d", r+1, len(line), cave.tileCols)
+		}
 
-// copied from eleven.go
-func (cave *Cave) updateNeighbours() {
-	maxRow := cave.height - 1
-	maxCol := cave.width - 1
-
-	for r, row := range cave.grid {
-		for c, cell := range row {
-			indices := [][]int{
-				{r + 1, c},
-				{r, c + 1},
-				{r - 1, c},
-				{r, c - 1},
+		row := make([]int, len(line))
+
+		for c := 0; c < len(line); c++ {
+			// risk has to be at least 1, for the heuristic
+			if line[c] < '1' || line[c] > '9' {
+				return nil, fmt.Errorf("row %d, col %d: %q is not a risk from 1-9", r+1, c+1, line[c])
 			}
 
-			for _, coords := range indices {
-				r, c := coords[0], coords[1]
+			row[c] = int(line[c] - '0')
+		}
 
-				if r < 0 || c < 0 || r > maxRow || c > maxCol {
-					continue
-				}
+		cave.tile = append(cave.tile, row)
+	}
 
-				cell.neighbours = append(cell.neighbours, cave.grid[r][c])
-			}
-		}
+	// searching needs a few bytes for every cell
+	if cave.tileRows*cave.tileCols > maxCells/multiplier/multiplier {
+		return nil, fmt.Errorf("cave is too big to search (over %d cells)", maxCells)
 	}
+
+	cave.height = cave.tileRows * multiplier
+	cave.width = cave.tileCols * multiplier
+
+	return cave, nil
 }
 
-func (cave *Cave) findAllPaths() {
-	pq := make(types.PriorityQueue[Cell], cave.height*cave.width)
-
-	for r, row := range cave.grid {
-		for c, cell := range row {
-			index := r*cave.width + c
-			pq.NewItem(
-				cell,
-				cell.distance,
-				index,
-			)
-		}
-	}
+func (cave *Cave) contains(p Point) bool {
+	return p.row >= 0 && p.col >= 0 && p.row < cave.height && p.col < cave.width
+}
 
-	pq.Init()
+// each repeat right or down adds 1, and anything above 9 wraps back to 1
+func (cave *Cave) risk(p Point) int {
+	base := cave.tile[p.row%cave.tileRows][p.col%cave.tileCols]
+	added := p.row/cave.tileRows + p.col/cave.tileCols
 
-	for pq.Len() > 0 {
-		cell := pq.Get()
+	return (base+added-1)%9 + 1
+}
 
-		for i := range cell.neighbours {
-			neighbour := cell.neighbours[i]
+func (cave *Cave) index(p Point) int {
+	return p.row*cave.width + p.col
+}
 
-			if neighbour.visited {
-				continue
-			}
+func (cave *Cave) point(i int) Point {
+	return Point{i / cave.width, i % cave.width}
+}
 
-			// update distance of neighbours
-			neighbour.distance = utils.MinInt(
-				neighbour.value+cell.distance,
-				neighbour.distance,
-			)
+// every risk is at least 1, so manhattan distance never overestimates
+func manhattan(a, b Point) int {
+	return utils.Abs(a.row-b.row) + utils.Abs(a.col-b.col)
+}
 
-			// hide the magic in the priority queue
-			pq.Update(neighbour, neighbour.distance)
-		}
+// top left to bottom right
+func (cave *Cave) lowestRisk() Route {
+	route, _ := newRouter(cave).route(Point{0, 0}, Point{cave.height - 1, cave.width - 1})
 
-		cell.visited = true
-	}
+	return route
 }
 
 //
@@ -152,24 +114,22 @@ func (cave *Cave) findAllPaths() {
 //
 
 // custom string representation
-func (cave *Cave) String() (output string) {
-	output += "[[ "
-	height := cave.height
-	for r, cells := range cave.grid {
-		for _, cell := range cells {
-			output += fmt.Sprint(cell.value)
+func (cave *Cave) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("[[ ")
+
+	for r := 0; r < cave.height; r++ {
+		for c := 0; c < cave.width; c++ {
+			sb.WriteByte(byte('0' + cave.risk(Point{r, c})))
 		}
 
-		if r < height-1 {
-			output += "\n   "
+		if r < cave.height-1 {
+			sb.WriteString("\n   ")
 		}
 	}
-	output += " ]]"
 
-	return
-}
+	sb.WriteString(" ]]")
 
-// custom string representation (fix recursion)
-func (cell *Cell) String() string {
-	return fmt.Sprint(cell.value)
+	return sb.String()
 }
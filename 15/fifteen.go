@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 
@@ -17,29 +18,41 @@ func init() {
 }
 
 func PartOne(content []string) (output int, err error) {
-	cave := newCave(content, 1)
+	cave, err := newCave(content, 1)
 
-	log.Println(cave.String())
+	if err != nil {
+		return
+	}
 
-	cave.findAllPaths()
+	log.Println(cave.String())
 
-	return cave.end.distance, nil
+	return cave.lowestRisk().risk, nil
 }
 
 func PartTwo(content []string) (output int, err error) {
-	cave := newCave(content, 5)
+	cave, err := newCave(content, 5)
 
-	log.Println(cave.String())
+	if err != nil {
+		return
+	}
 
-	cave.findAllPaths()
+	log.Println(cave.String())
 
-	return cave.end.distance, nil
+	return cave.lowestRisk().risk, nil
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	exportFlag := flag.String("export", "", "write the route to a .txt (ascii), .png or .pgm file")
+	multiplierFlag := flag.Int("multiplier", 5, "how many tiles across and down to route through")
+	fromFlag := flag.String("from", "", "start of the route, as row,col (defaults to the top left)")
+	toFlag := flag.String("to", "", "end of the route, as row,col (defaults to the bottom right)")
+	distancesFlag := flag.String("distances", "", "write a heatmap of the risk from -from to every cell to a .png or .pgm file")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -59,4 +72,72 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	if *exportFlag == "" && *distancesFlag == "" && *fromFlag == "" && *toFlag == "" {
+		return
+	}
+
+	cave, err := newCave(data, *multiplierFlag)
+
+	if err != nil {
+		fmt.Println("failed to load cave", err)
+		return
+	}
+
+	from, to := Point{0, 0}, Point{cave.height - 1, cave.width - 1}
+
+	for _, point := range []struct {
+		flagValue string
+		p         *Point
+	}{{*fromFlag, &from}, {*toFlag, &to}} {
+		if point.flagValue == "" {
+			continue
+		}
+
+		if *point.p, err = parsePoint(point.flagValue); err != nil {
+			fmt.Println("invalid point", err)
+			return
+		}
+	}
+
+	router := newRouter(cave)
+	route, err := router.route(from, to)
+
+	if err != nil {
+		fmt.Println("failed to route", err)
+		return
+	}
+
+	fmt.Printf("Route from %v to %v: risk %d, %d steps \n", from, to, route.risk, len(route.path)-1)
+
+	if *exportFlag != "" {
+		if err := cave.exportRoute(*exportFlag, route); err != nil {
+			fmt.Println("failed to export", err)
+			return
+		}
+
+		fmt.Printf("Wrote %s \n", *exportFlag)
+	}
+
+	if *distancesFlag != "" {
+		distances, err := router.distances(from)
+
+		if err == nil {
+			err = distances.export(*distancesFlag)
+		}
+
+		if err != nil {
+			fmt.Println("failed to export distances", err)
+			return
+		}
+
+		fmt.Printf("Wrote %s \n", *distancesFlag)
+	}
+}
+
+// "row,col"
+func parsePoint(str string) (p Point, err error) {
+	_, err = fmt.Sscanf(str, "%d,%d", &p.row, &p.col)
+
+	return
 }
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"math/rand"
 	"os"
 	"testing"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
 )
 
 // fill in the answers for each part (as they come)
@@ -68,3 +71,193 @@ func BenchmarkPartOne(b *testing.B) {
 		})
 	}
 }
+
+func TestVirtualTiles(t *testing.T) {
+	cave, _ := newCave([]string{"8"}, 5)
+
+	expected := "[[ 89123\n   91234\n   12345\n   23456\n   34567 ]]"
+
+	if cave.String() != expected {
+		t.Logf("expected %s, got %s", expected, cave.String())
+		t.Fail()
+	}
+
+	for _, data := range [][]string{{"123", "45"}, {"120"}, {}} {
+		if _, err := newCave(data, 1); err == nil {
+			t.Logf("expected an error for %v", data)
+			t.Fail()
+		}
+	}
+}
+
+// plain relaxation until nothing changes: slow, but obviously right
+func slowRisk(cave *Cave, from, to Point) int {
+	risk := map[Point]int{from: 0}
+
+	for changed := true; changed; {
+		changed = false
+
+		for r := 0; r < cave.height; r++ {
+			for c := 0; c < cave.width; c++ {
+				p := Point{r, c}
+
+				for _, dir := range directions {
+					prev := Point{r - dir.row, c - dir.col}
+					val, ok := risk[prev]
+
+					if !ok {
+						continue
+					}
+
+					if old, ok := risk[p]; !ok || val+cave.risk(p) < old {
+						risk[p] = val + cave.risk(p)
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	return risk[to]
+}
+
+func checkRoute(t *testing.T, cave *Cave, route Route) {
+	total := 0
+
+	for i := 1; i < len(route.path); i++ {
+		if manhattan(route.path[i-1], route.path[i]) != 1 {
+			t.Logf("route jumps from %v to %v", route.path[i-1], route.path[i])
+			t.Fail()
+		}
+
+		total += cave.risk(route.path[i])
+	}
+
+	if total != route.risk {
+		t.Logf("route adds up to %d, but says %d", total, route.risk)
+		t.Fail()
+	}
+}
+
+func TestLowestRiskRoute(t *testing.T) {
+	cave, _ := newCave(vals, 5)
+	route := cave.lowestRisk()
+
+	checkRoute(t, cave, route)
+
+	random := rand.New(rand.NewSource(15))
+
+	for i := 0; i < 20; i++ {
+		data := make([]string, 1+random.Intn(6))
+		cols := 1 + random.Intn(6)
+
+		for r := range data {
+			for c := 0; c < cols; c++ {
+				data[r] += string(rune('1' + random.Intn(9)))
+			}
+		}
+
+		cave, _ := newCave(data, 1+random.Intn(3))
+		route := cave.lowestRisk()
+		expected := slowRisk(cave, Point{0, 0}, Point{cave.height - 1, cave.width - 1})
+
+		if route.risk != expected {
+			t.Logf("%v: expected %d, got %d", data, expected, route.risk)
+			t.Fail()
+		}
+
+		checkRoute(t, cave, route)
+	}
+}
+
+func TestRenderRoute(t *testing.T) {
+	cave, _ := newCave([]string{"119", "919", "911"}, 1)
+	route := cave.lowestRisk()
+
+	expected := "11.\n.1.\n.11\n"
+
+	if got := cave.renderRoute(route); got != expected {
+		t.Logf("expected %q, got %q", expected, got)
+		t.Fail()
+	}
+
+	img, _ := cave.routeImage(route)
+
+	if img.RGBAAt(1, 1) != routeColor || img.RGBAAt(0, 1) == routeColor {
+		t.Log("route should be drawn on the image")
+		t.Fail()
+	}
+}
+
+func TestRouteQueries(t *testing.T) {
+	cave, _ := newCave(vals, 2)
+	router := newRouter(cave)
+	random := rand.New(rand.NewSource(45))
+
+	randomPoint := func() Point {
+		return Point{random.Intn(cave.height), random.Intn(cave.width)}
+	}
+
+	// one router, many queries
+	for i := 0; i < 30; i++ {
+		from, to := randomPoint(), randomPoint()
+		route, err := router.route(from, to)
+
+		if err != nil {
+			t.Log("error should be nil", err)
+			t.Fail()
+		}
+
+		if expected := slowRisk(cave, from, to); route.risk != expected {
+			t.Logf("%v to %v: expected %d, got %d", from, to, expected, route.risk)
+			t.Fail()
+		}
+
+		if route.path[0] != from || route.path[len(route.path)-1] != to {
+			t.Logf("%v to %v: route goes from %v to %v", from, to, route.path[0], route.path[len(route.path)-1])
+			t.Fail()
+		}
+
+		checkRoute(t, cave, route)
+	}
+
+	if _, err := router.route(Point{0, 0}, Point{cave.height, 0}); err == nil {
+		t.Log("expected an error for a point outside the cave")
+		t.Fail()
+	}
+}
+
+func TestDistanceMap(t *testing.T) {
+	cave, _ := newCave(vals, 1)
+	router := newRouter(cave)
+	origin := Point{4, 6}
+	distances, _ := router.distances(origin)
+
+	for r := 0; r < cave.height; r++ {
+		for c := 0; c < cave.width; c++ {
+			to := Point{r, c}
+			route, _ := router.route(origin, to)
+
+			if distances.at(to) != route.risk {
+				t.Logf("%v: expected %d, got %d", to, route.risk, distances.at(to))
+				t.Fail()
+			}
+
+			mapped, _ := distances.routeTo(to)
+
+			if mapped.risk != route.risk {
+				t.Logf("%v: routeTo says %d, expected %d", to, mapped.risk, route.risk)
+				t.Fail()
+			}
+
+			checkRoute(t, cave, mapped)
+		}
+	}
+
+	img, _ := distances.heatmap(utils.HeatRamp)
+
+	if img.RGBAAt(origin.col, origin.row) != utils.HeatRamp.At(0) {
+		t.Log("the origin should be the coolest cell")
+		t.Fail()
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+// anything bigger is almost certainly a mistake
+const maxImagePixels = 1 << 24
+
+var routeColor = color.RGBA{255, 40, 40, 255}
+
+func (route Route) cells() map[Point]bool {
+	onRoute := map[Point]bool{}
+
+	for _, p := range route.path {
+		onRoute[p] = true
+	}
+
+	return onRoute
+}
+
+// the cave with only the route's risks showing
+func (cave *Cave) renderRoute(route Route) string {
+	onRoute := route.cells()
+
+	var sb strings.Builder
+
+	for r := 0; r < cave.height; r++ {
+		for c := 0; c < cave.width; c++ {
+			p := Point{r, c}
+
+			if onRoute[p] {
+				sb.WriteByte(byte('0' + cave.risk(p)))
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// one pixel per cell: risks in gray (darker is safer), and the route in red
+func (cave *Cave) routeImage(route Route) (*image.RGBA, error) {
+	if cave.height*cave.width > maxImagePixels {
+		return nil, errors.New("cave is too large for an image")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cave.width, cave.height))
+
+	for r := 0; r < cave.height; r++ {
+		for c := 0; c < cave.width; c++ {
+			risk := cave.risk(Point{r, c})
+			img.SetRGBA(c, r, utils.GrayRamp.At(float64(risk-1)/8))
+		}
+	}
+
+	for _, p := range route.path {
+		img.SetRGBA(p.col, p.row, routeColor)
+	}
+
+	return img, nil
+}
+
+// .txt for ascii, otherwise a .png or .pgm image
+func (cave *Cave) exportRoute(filename string, route Route) error {
+	if filepath.Ext(filename) == ".txt" {
+		return os.WriteFile(filename, []byte(cave.renderRoute(route)), 0644)
+	}
+
+	img, err := cave.routeImage(route)
+
+	if err != nil {
+		return err
+	}
+
+	return utils.SaveImage(filename, img)
+}
+
+// one pixel per cell: low risk from the origin is dark, high is bright
+func (distances *DistanceMap) heatmap(ramp utils.ColorRamp) (*image.RGBA, error) {
+	cave := distances.cave
+
+	if cave.height*cave.width > maxImagePixels {
+		return nil, errors.New("cave is too large for an image")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cave.width, cave.height))
+	most := float64(utils.MaxInt(distances.max(), 1))
+
+	for r := 0; r < cave.height; r++ {
+		for c := 0; c < cave.width; c++ {
+			img.SetRGBA(c, r, ramp.At(float64(distances.at(Point{r, c}))/most))
+		}
+	}
+
+	return img, nil
+}
+
+// a .png or .pgm heatmap of the distances
+func (distances *DistanceMap) export(filename string) error {
+	img, err := distances.heatmap(utils.HeatRamp)
+
+	if err != nil {
+		return err
+	}
+
+	return utils.SaveImage(filename, img)
+}
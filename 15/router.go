@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// the lowest-risk way from one point to another; the start's risk
+// doesn't count, since you never enter it
+type Route struct {
+	path []Point
+	risk int
+}
+
+// Router answers any number of route queries on one cave, reusing
+// its buffers so each query only pays for the cells it touches
+type Router struct {
+	cave *Cave
+	risk []int
+	// the direction we came from (+1), so 0 means unvisited
+	came   []byte
+	closed []bool
+	// cells to reset before the next query
+	touched []int
+}
+
+func newRouter(cave *Cave) *Router {
+	n := cave.height * cave.width
+	router := &Router{
+		cave:   cave,
+		risk:   make([]int, n),
+		came:   make([]byte, n),
+		closed: make([]bool, n),
+	}
+
+	for i := range router.risk {
+		router.risk[i] = math.MaxInt
+	}
+
+	return router
+}
+
+func (router *Router) reset() {
+	for _, i := range router.touched {
+		router.risk[i] = math.MaxInt
+		router.came[i] = 0
+		router.closed[i] = false
+	}
+
+	router.touched = router.touched[:0]
+}
+
+// A* over a bucket queue: a step costs at most 9 and the heuristic
+// changes by at most 1, so estimates only ever grow by up to 10 and
+// a ring of 11 buckets holds everything still open; without a goal
+// (to is nil) it's plain Dijkstra over every cell
+func (router *Router) search(from Point, to *Point) {
+	const buckets = 9 + 1 + 1
+
+	cave := router.cave
+	heuristic := func(p Point) int {
+		if to == nil {
+			return 0
+		}
+
+		return manhattan(p, *to)
+	}
+
+	router.reset()
+
+	queue := [buckets][]int{}
+	start := cave.index(from)
+	estimate := heuristic(from)
+	open := 1
+
+	router.risk[start] = 0
+	router.touched = append(router.touched, start)
+	queue[estimate%buckets] = append(queue[estimate%buckets], start)
+
+	for open > 0 {
+		bucket := &queue[estimate%buckets]
+
+		if len(*bucket) == 0 {
+			estimate++
+			continue
+		}
+
+		current := (*bucket)[len(*bucket)-1]
+		*bucket = (*bucket)[:len(*bucket)-1]
+		open--
+
+		p := cave.point(current)
+
+		// stale: it was queued again with a lower risk
+		if router.closed[current] || router.risk[current]+heuristic(p) != estimate {
+			continue
+		}
+
+		if to != nil && p == *to {
+			return
+		}
+
+		router.closed[current] = true
+
+		for d, dir := range directions {
+			next := Point{p.row + dir.row, p.col + dir.col}
+
+			if !cave.contains(next) {
+				continue
+			}
+
+			i := cave.index(next)
+			nextRisk := router.risk[current] + cave.risk(next)
+
+			if router.closed[i] || nextRisk >= router.risk[i] {
+				continue
+			}
+
+			if router.came[i] == 0 {
+				router.touched = append(router.touched, i)
+			}
+
+			router.risk[i] = nextRisk
+			router.came[i] = byte(d + 1)
+			f := nextRisk + heuristic(next)
+			queue[f%buckets] = append(queue[f%buckets], i)
+			open++
+		}
+	}
+}
+
+// walks back from `to` using the directions from the last search
+func (router *Router) path(from, to Point) []Point {
+	path := []Point{to}
+
+	for p := to; p != from; {
+		dir := directions[router.came[router.cave.index(p)]-1]
+		p = Point{p.row - dir.row, p.col - dir.col}
+		path = append(path, p)
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+func (router *Router) check(points ...Point) error {
+	for _, p := range points {
+		if !router.cave.contains(p) {
+			return fmt.Errorf("%v is outside the cave (%dx%d)", p, router.cave.height, router.cave.width)
+		}
+	}
+
+	return nil
+}
+
+// the lowest-risk route between any two cells
+func (router *Router) route(from, to Point) (Route, error) {
+	if err := router.check(from, to); err != nil {
+		return Route{}, err
+	}
+
+	router.search(from, &to)
+
+	return Route{router.path(from, to), router.risk[router.cave.index(to)]}, nil
+}
+
+// DistanceMap is the lowest risk from one origin to every cell
+type DistanceMap struct {
+	cave   *Cave
+	origin Point
+	risk   []int
+	came   []byte
+}
+
+// single-source: every cell's lowest risk from origin
+func (router *Router) distances(origin Point) (*DistanceMap, error) {
+	if err := router.check(origin); err != nil {
+		return nil, err
+	}
+
+	router.search(origin, nil)
+
+	// copies, so the router can keep going
+	return &DistanceMap{
+		cave:   router.cave,
+		origin: origin,
+		risk:   append([]int(nil), router.risk...),
+		came:   append([]byte(nil), router.came...),
+	}, nil
+}
+
+func (distances *DistanceMap) at(p Point) int {
+	return distances.risk[distances.cave.index(p)]
+}
+
+// the highest risk to reach any cell
+func (distances *DistanceMap) max() (most int) {
+	for _, risk := range distances.risk {
+		if risk > most {
+			most = risk
+		}
+	}
+
+	return
+}
+
+// the lowest-risk route from the origin to any cell, without searching again
+func (distances *DistanceMap) routeTo(to Point) (Route, error) {
+	if !distances.cave.contains(to) {
+		return Route{}, fmt.Errorf("%v is outside the cave", to)
+	}
+
+	router := &Router{cave: distances.cave, came: distances.came}
+
+	return Route{router.path(distances.origin, to), distances.at(to)}, nil
+}
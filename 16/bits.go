@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrOverRead         = errors.New("read past the end of the transmission")
+	ErrTruncatedLiteral = errors.New("literal value ends without a last group")
+	ErrLiteralOverflow  = errors.New("literal value is too big for an int")
+	ErrBadLengthType    = errors.New("sub-packets don't match the operator's length")
+	ErrTrailingBits     = errors.New("non-zero bits after the outermost packet")
+)
+
+// ParseError is where (in bits, from the start) parsing, or
+// evaluating, went wrong
+type ParseError struct {
+	Pos    int
+	Err    error
+	Detail string
+}
+
+func (err *ParseError) Error() string {
+	if err.Detail == "" {
+		return fmt.Sprintf("bit %d: %s", err.Pos, err.Err)
+	}
+
+	return fmt.Sprintf("bit %d: %s (%s)", err.Pos, err.Err, err.Detail)
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// BitReader reads big-endian bits from bytes, without copying them
+type BitReader struct {
+	data []byte
+	// bits read so far
+	pos int
+	// reads stop here: the end of the data, or of the operator
+	// that's being read
+	limit int
+}
+
+func newReader(data []byte) *BitReader {
+	return &BitReader{data: data, limit: len(data) * 8}
+}
+
+// hex to bytes; an odd number of digits gets a zero nibble at the end
+func decodeHex(hexStr string) ([]byte, error) {
+	hexStr = strings.TrimSpace(hexStr)
+
+	if len(hexStr)%2 == 1 {
+		hexStr += "0"
+	}
+
+	return hex.DecodeString(hexStr)
+}
+
+func (reader *BitReader) remaining() int {
+	return reader.limit - reader.pos
+}
+
+func (reader *BitReader) fail(pos int, err error, detail string) error {
+	return &ParseError{pos, err, detail}
+}
+
+// the next n bits (up to 64) as a number
+func (reader *BitReader) read(n int) (uint64, error) {
+	if n < 0 || n > 64 {
+		return 0, fmt.Errorf("can't read %d bits at once", n)
+	}
+
+	if n > reader.remaining() {
+		return 0, reader.fail(reader.pos, ErrOverRead, fmt.Sprintf("wanted %d bits, %d left", n, reader.remaining()))
+	}
+
+	var val uint64
+
+	for n > 0 {
+		// as many bits as are left in the current byte
+		offset := reader.pos % 8
+		take := 8 - offset
+
+		if take > n {
+			take = n
+		}
+
+		bits := reader.data[reader.pos/8] >> (8 - offset - take) & (1<<take - 1)
+
+		val = val<<take | uint64(bits)
+		reader.pos += take
+		n -= take
+	}
+
+	return val, nil
+}
+
+func (reader *BitReader) readInt(n int) (int, error) {
+	val, err := reader.read(n)
+
+	return int(val), err
+}
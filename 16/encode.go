@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// the biggest numbers the length fields can hold
+const (
+	maxBitLength   = 1<<15 - 1
+	maxPacketCount = 1<<11 - 1
+)
+
+// BitWriter is the reverse of BitReader
+type BitWriter struct {
+	data []byte
+	// bits written so far
+	pos int
+}
+
+// writes the low n bits of val
+func (writer *BitWriter) write(val uint64, n int) {
+	for n > 0 {
+		n--
+
+		if writer.pos%8 == 0 {
+			writer.data = append(writer.data, 0)
+		}
+
+		writer.data[writer.pos/8] |= byte(val>>n&1) << (7 - writer.pos%8)
+		writer.pos++
+	}
+}
+
+// overwrites n bits at pos, which have to have been written already
+func (writer *BitWriter) writeAt(pos int, val uint64, n int) {
+	for i := 0; i < n; i++ {
+		bit := pos + i
+		mask := byte(1) << (7 - bit%8)
+
+		if val>>(n-1-i)&1 == 1 {
+			writer.data[bit/8] |= mask
+		} else {
+			writer.data[bit/8] &^= mask
+		}
+	}
+}
+
+// a literal packet
+func newLiteral(version, value int) *Packet {
+	return &Packet{
+		version:            version,
+		typeId:             TYPE_LITERAL,
+		LiteralValuePacket: LiteralValuePacket{value},
+	}
+}
+
+// an operator packet; the packet count is shorter to write, so
+// that's the length type unless there are too many sub-packets
+func newOperator(version int, typeId TypeId, packets ...Packet) *Packet {
+	lengthTypeId := LENGTH_PACKETS
+
+	if len(packets) > maxPacketCount {
+		lengthTypeId = LENGTH_BITS
+	}
+
+	return &Packet{
+		version:        version,
+		typeId:         typeId,
+		OperatorPacket: OperatorPacket{lengthTypeId, packets},
+	}
+}
+
+func (packet *Packet) encode(writer *BitWriter) error {
+	if packet.version < 0 || packet.version > 7 {
+		return fmt.Errorf("version %d doesn't fit in 3 bits", packet.version)
+	}
+
+	if packet.typeId < 0 || packet.typeId > 7 {
+		return fmt.Errorf("type id %d doesn't fit in 3 bits", packet.typeId)
+	}
+
+	writer.write(uint64(packet.version), 3)
+	writer.write(uint64(packet.typeId), 3)
+
+	if packet.typeId == TYPE_LITERAL {
+		return packet.encodeLiteral(writer)
+	}
+
+	if len(packet.packets) == 0 {
+		return errors.New("operators need at least one sub-packet")
+	}
+
+	writer.write(uint64(packet.lengthTypeId), 1)
+
+	switch packet.lengthTypeId {
+	case LENGTH_BITS:
+		// filled in once we know how long the sub-packets are
+		lengthPos := writer.pos
+		writer.write(0, 15)
+
+		for i := range packet.packets {
+			if err := packet.packets[i].encode(writer); err != nil {
+				return err
+			}
+		}
+
+		bitLength := writer.pos - lengthPos - 15
+
+		if bitLength > maxBitLength {
+			return fmt.Errorf("sub-packets are %d bits, but the length only fits %d", bitLength, maxBitLength)
+		}
+
+		writer.writeAt(lengthPos, uint64(bitLength), 15)
+	case LENGTH_PACKETS:
+		if len(packet.packets) > maxPacketCount {
+			return fmt.Errorf("%d sub-packets, but the count only fits %d", len(packet.packets), maxPacketCount)
+		}
+
+		writer.write(uint64(len(packet.packets)), 11)
+
+		for i := range packet.packets {
+			if err := packet.packets[i].encode(writer); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown length type %d", packet.lengthTypeId)
+	}
+
+	return nil
+}
+
+// as few groups of 4 bits as possible
+func (packet *Packet) encodeLiteral(writer *BitWriter) error {
+	if packet.value < 0 {
+		return fmt.Errorf("literal %d is negative", packet.value)
+	}
+
+	groups := 1
+
+	for packet.value>>(4*groups) != 0 {
+		groups++
+	}
+
+	for i := groups - 1; i >= 0; i-- {
+		more := uint64(0)
+
+		if i > 0 {
+			more = 1
+		}
+
+		writer.write(more<<4|uint64(packet.value>>(4*i)&0b1111), 5)
+	}
+
+	return nil
+}
+
+// the whole packet as a hex transmission
+func (packet *Packet) encodeHex() (string, error) {
+	writer := &BitWriter{}
+
+	if err := packet.encode(writer); err != nil {
+		return "", err
+	}
+
+	return strings.ToUpper(hex.EncodeToString(writer.data)), nil
+}
+
+// same tree: offsets are left out, since they depend on the encoding
+func (packet *Packet) equal(other *Packet) bool {
+	if packet.version != other.version || packet.typeId != other.typeId {
+		return false
+	}
+
+	if packet.typeId == TYPE_LITERAL {
+		return packet.value == other.value
+	}
+
+	if packet.lengthTypeId != other.lengthTypeId || len(packet.packets) != len(other.packets) {
+		return false
+	}
+
+	for i := range packet.packets {
+		if !packet.packets[i].equal(&other.packets[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//
+// -- S-expressions --
+//
+// a literal is a number, and an operator is a list:
+//
+//	(sum 1 (product 2 3))
+//
+// versions go after a colon (default 0), and a length type after
+// a slash (default is chosen by newOperator):
+//
+//	(max:7/0 5:1 6)
+//
+
+var operatorNames = map[TypeId]string{
+	TYPE_SUM:     "sum",
+	TYPE_PRODUCT: "product",
+	TYPE_MIN:     "min",
+	TYPE_MAX:     "max",
+	TYPE_GT:      "gt",
+	TYPE_LT:      "lt",
+	TYPE_EQ:      "eq",
+}
+
+func operatorType(name string) (TypeId, bool) {
+	for typeId, opName := range operatorNames {
+		if opName == name {
+			return typeId, true
+		}
+	}
+
+	return 0, false
+}
+
+// the packet as an S-expression; parseSExpr reads it back
+func (packet *Packet) sexpr() string {
+	if packet.typeId == TYPE_LITERAL {
+		return fmt.Sprintf("%d:%d", packet.value, packet.version)
+	}
+
+	parts := []string{fmt.Sprintf("%s:%d/%d", operatorNames[packet.typeId], packet.version, packet.lengthTypeId)}
+
+	for i := range packet.packets {
+		parts = append(parts, packet.packets[i].sexpr())
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func tokenize(str string) []string {
+	str = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(str)
+
+	return strings.FieldsFunc(str, unicode.IsSpace)
+}
+
+// "name:version/lengthType", where both suffixes are optional
+func splitAtom(atom string) (name string, version int, lengthType int, err error) {
+	name, lengthType = atom, -1
+
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		if lengthType, err = strconv.Atoi(name[i+1:]); err != nil || lengthType > 1 || lengthType < 0 {
+			return "", 0, 0, fmt.Errorf("bad length type in %q", atom)
+		}
+
+		name = name[:i]
+	}
+
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		if version, err = strconv.Atoi(name[i+1:]); err != nil || version < 0 || version > 7 {
+			return "", 0, 0, fmt.Errorf("bad version in %q", atom)
+		}
+
+		name = name[:i]
+	}
+
+	return
+}
+
+func parseSExpr(str string) (*Packet, error) {
+	tokens := tokenize(str)
+	packet, rest, err := parseTokens(tokens)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected %q after the packet", rest[0])
+	}
+
+	return packet, nil
+}
+
+func parseTokens(tokens []string) (*Packet, []string, error) {
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("unexpected end of expression")
+	}
+
+	token, tokens := tokens[0], tokens[1:]
+
+	if token == ")" {
+		return nil, nil, errors.New("unexpected )")
+	}
+
+	if token != "(" {
+		name, version, lengthType, err := splitAtom(token)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value, err := strconv.Atoi(name)
+
+		if err != nil || value < 0 || lengthType != -1 {
+			return nil, nil, fmt.Errorf("%q is not a literal", token)
+		}
+
+		return newLiteral(version, value), tokens, nil
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("unexpected end of expression")
+	}
+
+	name, version, lengthType, err := splitAtom(tokens[0])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typeId, ok := operatorType(name)
+
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown operator %q", name)
+	}
+
+	tokens = tokens[1:]
+	packets := []Packet{}
+
+	for len(tokens) > 0 && tokens[0] != ")" {
+		var subpacket *Packet
+
+		if subpacket, tokens, err = parseTokens(tokens); err != nil {
+			return nil, nil, err
+		}
+
+		packets = append(packets, *subpacket)
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("missing ) for %q", name)
+	}
+
+	if len(packets) == 0 {
+		return nil, nil, fmt.Errorf("%q needs at least one operand", name)
+	}
+
+	packet := newOperator(version, typeId, packets...)
+
+	if lengthType != -1 {
+		packet.lengthTypeId = LengthTypeId(lengthType)
+	}
+
+	// skip the )
+	return packet, tokens[1:], nil
+}
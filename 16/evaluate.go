@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrArity = errors.New("wrong number of operands")
+
+// how many sub-packets each operator takes; -1 is no upper limit
+func arity(typeId TypeId) (min, max int) {
+	switch typeId {
+	case TYPE_LITERAL:
+		return 0, 0
+	case TYPE_GT, TYPE_LT, TYPE_EQ:
+		return 2, 2
+	}
+
+	return 1, -1
+}
+
+func (packet *Packet) checkArity() error {
+	min, max := arity(packet.typeId)
+	got := len(packet.packets)
+
+	if got >= min && (max == -1 || got <= max) {
+		return nil
+	}
+
+	want := fmt.Sprintf("at least %d", min)
+
+	if min == max {
+		want = fmt.Sprintf("exactly %d", min)
+	}
+
+	return &ParseError{packet.offset, ErrArity, fmt.Sprintf("%s takes %s, got %d", packet.name(), want, got)}
+}
+
+func (packet *Packet) name() string {
+	if packet.typeId == TYPE_LITERAL {
+		return "literal"
+	}
+
+	if name, ok := operatorNames[packet.typeId]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("type %d", packet.typeId)
+}
+
+// Part Two introduces operator logic; products can get big, so
+// it's all done with big.Int
+func (packet *Packet) evaluate() (*big.Int, error) {
+	if err := packet.checkArity(); err != nil {
+		return nil, err
+	}
+
+	if packet.typeId == TYPE_LITERAL {
+		return big.NewInt(int64(packet.value)), nil
+	}
+
+	values := make([]*big.Int, len(packet.packets))
+
+	// recursively check nested packets
+	for i := range packet.packets {
+		value, err := packet.packets[i].evaluate()
+
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = value
+	}
+
+	result := new(big.Int).Set(values[0])
+
+	switch packet.typeId {
+	case TYPE_SUM:
+		for _, val := range values[1:] {
+			result.Add(result, val)
+		}
+	case TYPE_PRODUCT:
+		for _, val := range values[1:] {
+			result.Mul(result, val)
+		}
+	case TYPE_MIN:
+		for _, val := range values[1:] {
+			if val.Cmp(result) < 0 {
+				result.Set(val)
+			}
+		}
+	case TYPE_MAX:
+		for _, val := range values[1:] {
+			if val.Cmp(result) > 0 {
+				result.Set(val)
+			}
+		}
+	case TYPE_GT:
+		result.SetInt64(boolToInt(values[0].Cmp(values[1]) > 0))
+	case TYPE_LT:
+		result.SetInt64(boolToInt(values[0].Cmp(values[1]) < 0))
+	case TYPE_EQ:
+		result.SetInt64(boolToInt(values[0].Cmp(values[1]) == 0))
+	}
+
+	return result, nil
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// how tightly each form binds, for deciding on parentheses
+const (
+	precCompare = iota + 1
+	precSum
+	precProduct
+	// literals and function calls never need parentheses
+	precAtom
+)
+
+var infixOperators = map[TypeId]string{
+	TYPE_SUM:     " + ",
+	TYPE_PRODUCT: " * ",
+	TYPE_GT:      " > ",
+	TYPE_LT:      " < ",
+	TYPE_EQ:      " == ",
+}
+
+var infixPrecedence = map[TypeId]int{
+	TYPE_SUM:     precSum,
+	TYPE_PRODUCT: precProduct,
+	TYPE_GT:      precCompare,
+	TYPE_LT:      precCompare,
+	TYPE_EQ:      precCompare,
+}
+
+// the packet as a readable expression, like "max(3, 7 * 2) > 5"
+func (packet *Packet) infix() string {
+	str, _ := packet.infixPrec()
+
+	return str
+}
+
+func (packet *Packet) infixPrec() (string, int) {
+	if packet.typeId == TYPE_LITERAL {
+		return fmt.Sprint(packet.value), precAtom
+	}
+
+	op, isInfix := infixOperators[packet.typeId]
+	prec := infixPrecedence[packet.typeId]
+	operands := make([]string, len(packet.packets))
+
+	// anything that isn't a binary (or longer) sum, product or valid
+	// comparison is written as a call: min(1, 2), sum(3), gt(1, 2, 3)
+	if !isInfix || len(packet.packets) < 2 || packet.checkArity() != nil {
+		for i := range packet.packets {
+			operands[i] = packet.packets[i].infix()
+		}
+
+		return packet.name() + "(" + strings.Join(operands, ", ") + ")", precAtom
+	}
+
+	for i := range packet.packets {
+		str, childPrec := packet.packets[i].infixPrec()
+
+		// sums and products are associative, comparisons aren't
+		if childPrec < prec || (prec == precCompare && childPrec == prec) {
+			str = "(" + str + ")"
+		}
+
+		operands[i] = str
+	}
+
+	return strings.Join(operands, op), prec
+}
+
+// one packet per line, indented by depth, with versions and bit offsets
+func (packet *Packet) tree() string {
+	var sb strings.Builder
+
+	packet.writeTree(&sb, 0)
+
+	return sb.String()
+}
+
+func (packet *Packet) writeTree(sb *strings.Builder, depth int) {
+	label := packet.name()
+
+	if packet.typeId == TYPE_LITERAL {
+		label = fmt.Sprint(packet.value)
+	}
+
+	fmt.Fprintf(sb, "%s%s (v%d, bit %d)\n", strings.Repeat("  ", depth), label, packet.version, packet.offset)
+
+	for i := range packet.packets {
+		packet.packets[i].writeTree(sb, depth+1)
+	}
+}
@@ -1,10 +1,9 @@
 package main
 
 import (
-	"encoding/hex"
 	"errors"
 	"fmt"
-	"strings"
+	"math"
 
 	"github.com/bozdoz/advent-of-code-2021/utils"
 )
@@ -47,188 +46,204 @@ const (
 type Packet struct {
 	version int
 	typeId  TypeId
+	// bit offset of the packet in the transmission
+	offset int
 	LiteralValuePacket
 	OperatorPacket
 }
 
-type Binary string
+// parses a whole transmission: one packet, then nothing but zeros
+func parseTransmission(hexStr string) (*Packet, error) {
+	data, err := decodeHex(hexStr)
 
-func (binary Binary) splitAt(index int) (Binary, Binary) {
-	return binary[:index], binary[index:]
-}
-
-func hexToBinary(hexStr string) (binary Binary, err error) {
-	log.Println("hex", hexStr)
+	if err != nil {
+		return nil, err
+	}
 
-	decoded, err := hex.DecodeString(hexStr)
+	reader := newReader(data)
+	packet, err := parsePacket(reader)
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	for _, bit := range decoded {
-		// the binary number is padded with leading zeroes
-		// until its length is a multiple of four bits
-		// TODO: is %08b always right?
-		binary += Binary(fmt.Sprintf("%08b", int(bit)))
-	}
+	// the rest is padding, checked 64 bits at a time
+	for reader.remaining() > 0 {
+		pos := reader.pos
+		bits, _ := reader.read(utils.MinInt(64, reader.remaining()))
 
-	log.Println("binary", binary)
+		if bits != 0 {
+			return nil, reader.fail(pos, ErrTrailingBits, "")
+		}
+	}
 
-	return binary, err
+	return packet, nil
 }
 
-func newPacket(binary Binary) (packet *Packet, tail Binary, err error) {
+// parses the next packet (and all of its sub-packets)
+func parsePacket(reader *BitReader) (*Packet, error) {
+	packet := &Packet{offset: reader.pos}
+
 	// first 3 bits = version
-	head, tail := binary.splitAt(3)
-	version, err := utils.BinaryToInt(head)
+	version, err := reader.readInt(3)
+
+	if err != nil {
+		return nil, err
+	}
 
 	// next 3 bits = type id
-	head, tail = tail.splitAt(3)
-	typeId, err := utils.BinaryToInt(head)
+	typeId, err := reader.readInt(3)
 
-	packet = &Packet{
-		version: version,
-		typeId:  TypeId(typeId),
+	if err != nil {
+		return nil, err
 	}
 
-	log.Println("packet", packet)
+	packet.version = version
+	packet.typeId = TypeId(typeId)
+
+	log.Println("packet", packet.offset, packet.version, packet.typeId)
 
 	// typeId 4 is literal value;
 	if packet.typeId == TYPE_LITERAL {
-		tail, err = packet.parseLiteralValue(tail)
+		err = packet.parseLiteralValue(reader)
 	} else {
 		// every other typeId is an "operator"
-		tail, err = packet.parseOperator(tail)
+		err = packet.parseOperator(reader)
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	return packet, tail, err
+	return packet, nil
 }
 
-func (packet *Packet) parseLiteralValue(binary Binary) (tail Binary, err error) {
+func (packet *Packet) parseLiteralValue(reader *BitReader) error {
 	// "groups of five bits"
-	chunkSize := 5
+	for {
+		pos := reader.pos
+		group, err := reader.read(5)
 
-	// get literal value from remaining bits
-	var valueBin Binary
-	head, tail := binary.splitAt(chunkSize)
+		if err != nil {
+			return reader.fail(pos, ErrTruncatedLiteral, fmt.Sprintf("%d bits left", reader.remaining()))
+		}
 
-	for len(head) == 5 {
-		// strip off the leading bit
-		first, rest := head.splitAt(1)
+		// another 4 bits would overflow
+		if packet.value > math.MaxInt>>4 {
+			return reader.fail(pos, ErrLiteralOverflow, "")
+		}
 
-		valueBin += rest
+		packet.value = packet.value<<4 | int(group&0b1111)
 
 		// 0 means it is the last group
-		if first == "0" {
+		if group>>4 == 0 {
 			break
 		}
-
-		head, tail = tail.splitAt(5)
-	}
-
-	value, err := utils.BinaryToInt(valueBin)
-
-	if err != nil {
-		return tail, err
 	}
 
-	log.Println("value", value)
+	log.Println("value", packet.value)
 
-	packet.value = value
-
-	return tail, nil
+	return nil
 }
 
-func (packet *Packet) parseOperator(binary Binary) (tail Binary, err error) {
+func (packet *Packet) parseOperator(reader *BitReader) error {
 	// get length type id
-	head, tail := binary.splitAt(1)
+	lengthTypeId, err := reader.readInt(1)
 
-	if head == "0" {
-		packet.lengthTypeId = LENGTH_BITS
-		tail, err = packet.parseOperatorBitCount(tail)
-	} else {
-		packet.lengthTypeId = LENGTH_PACKETS
-		tail, err = packet.parseOperatorPacketCount(tail)
+	if err != nil {
+		return err
+	}
+
+	packet.lengthTypeId = LengthTypeId(lengthTypeId)
+
+	if packet.lengthTypeId == LENGTH_BITS {
+		return packet.parseOperatorBitCount(reader)
 	}
 
-	return tail, err
+	return packet.parseOperatorPacketCount(reader)
 }
 
 // the next 15 bits are a number that represents
 // the total length in bits of the sub-packets
-func (packet *Packet) parseOperatorBitCount(binary Binary) (tail Binary, err error) {
-	if packet.lengthTypeId != LENGTH_BITS {
-		return binary, errors.New("can't parse bit count from packet count")
+func (packet *Packet) parseOperatorBitCount(reader *BitReader) error {
+	bitCount, err := reader.readInt(15)
+
+	if err != nil {
+		return err
 	}
 
-	// 15 bits
-	head, tail := binary.splitAt(15)
+	log.Println("bitcount", bitCount)
 
-	bitCount, err := utils.BinaryToInt(head)
+	start := reader.pos
+	end := start + bitCount
 
-	log.Println("bitcount", bitCount)
+	if bitCount == 0 {
+		return reader.fail(start, ErrBadLengthType, "no sub-packets")
+	}
 
-	if err != nil {
-		return binary, err
+	if bitCount > reader.remaining() {
+		return reader.fail(start, ErrOverRead, fmt.Sprintf("sub-packets are %d bits, %d left", bitCount, reader.remaining()))
 	}
 
-	subpackets, tail := tail.splitAt(bitCount)
+	// sub-packets can't read past their operator's length
+	outer := reader.limit
+	reader.limit = end
 
-	log.Println("subpackets", subpackets)
-	log.Println("tail", tail)
+	defer func() {
+		reader.limit = outer
+	}()
 
 	// could be adjacent or nested packets
-	// TODO: bit of guessing here...
-	// ? discard any tail values that are exactly 0
-	for subpackets != Binary(strings.Repeat("0", len(subpackets))) {
-		subpacket, nextPackets, err := newPacket(subpackets)
+	for reader.pos < end {
+		pos := reader.pos
+		subpacket, err := parsePacket(reader)
 
 		if err != nil {
-			return tail, nil
+			// cut off by the operator's length, rather than the end of the data
+			cutOff := errors.Is(err, ErrOverRead) || errors.Is(err, ErrTruncatedLiteral)
+
+			if cutOff && end < outer {
+				return reader.fail(pos, ErrBadLengthType, fmt.Sprintf("sub-packet runs past bit %d", end))
+			}
+
+			return err
 		}
 
 		packet.packets = append(packet.packets, *subpacket)
-
-		subpackets = nextPackets
 	}
 
-	return tail, nil
+	return nil
 }
 
 // the next 11 bits are a number that represents
 // the number of sub-packets immediately contained
-func (packet *Packet) parseOperatorPacketCount(binary Binary) (tail Binary, err error) {
-	if packet.lengthTypeId != LENGTH_PACKETS {
-		return binary, errors.New("can't parse packet count from bit count")
-	}
+func (packet *Packet) parseOperatorPacketCount(reader *BitReader) error {
+	pos := reader.pos
+	packetCount, err := reader.readInt(11)
 
-	// 11 bits
-	head, tail := binary.splitAt(11)
-
-	packetCount, err := utils.BinaryToInt(head)
+	if err != nil {
+		return err
+	}
 
 	log.Println("packetCount", packetCount)
 
-	if err != nil {
-		return binary, err
+	if packetCount == 0 {
+		return reader.fail(pos, ErrBadLengthType, "no sub-packets")
 	}
 
-	for packetCount > 0 {
-		packetCount--
+	packet.packets = make([]Packet, 0, packetCount)
 
-		subpacket, nextTail, err := newPacket(tail)
+	for ; packetCount > 0; packetCount-- {
+		subpacket, err := parsePacket(reader)
 
 		if err != nil {
-			return tail, nil
+			return err
 		}
 
 		packet.packets = append(packet.packets, *subpacket)
-
-		tail = nextTail
 	}
 
-	return tail, err
+	return nil
 }
 
 func (packet *Packet) versionSum() (sum int) {
@@ -241,51 +256,3 @@ func (packet *Packet) versionSum() (sum int) {
 
 	return
 }
-
-// Part Two introduces operator logic
-func (packet *Packet) evaluateExpression() int {
-	if packet.typeId == TYPE_LITERAL {
-		return packet.value
-	}
-
-	values := []int{}
-
-	// recursively check nested packets
-	for _, subpacket := range packet.packets {
-		values = append(values, subpacket.evaluateExpression())
-	}
-
-	switch packet.typeId {
-	case TYPE_SUM:
-		return utils.Sum(values...)
-	case TYPE_PRODUCT:
-		product := 1
-
-		for _, val := range values {
-			product *= val
-		}
-
-		return product
-	case TYPE_MIN:
-		return utils.MinInt(values...)
-	case TYPE_MAX:
-		return utils.MaxInt(values...)
-	case TYPE_GT:
-		if values[0] > values[1] {
-			return 1
-		}
-		return 0
-	case TYPE_LT:
-		if values[0] < values[1] {
-			return 1
-		}
-		return 0
-	case TYPE_EQ:
-		if values[0] == values[1] {
-			return 1
-		}
-		return 0
-	}
-
-	return 0
-}
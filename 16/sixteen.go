@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"time"
@@ -20,41 +21,87 @@ func init() {
 }
 
 func PartOne(content string) (output int, err error) {
-	binary, err := hexToBinary(content)
+	packet, err := parseTransmission(content)
 
 	if err != nil {
 		return 0, err
 	}
 
-	packet, _, err := newPacket(binary)
+	return packet.versionSum(), nil
+}
+
+func PartTwo(content string) (output int, err error) {
+	packet, err := parseTransmission(content)
 
 	if err != nil {
 		return 0, err
 	}
 
-	return packet.versionSum(), nil
+	value, err := packet.evaluate()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !value.IsInt64() {
+		return 0, fmt.Errorf("%s doesn't fit in an int", value)
+	}
+
+	return int(value.Int64()), nil
 }
 
-func PartTwo(content string) (output int, err error) {
-	binary, err := hexToBinary(content)
+// the input's packets as an expression and as a tree
+func printPacket(content string, infix, tree bool) {
+	packet, err := parseTransmission(content)
 
 	if err != nil {
-		return 0, err
+		fmt.Println("failed to parse", err)
+		return
 	}
 
-	packet, _, err := newPacket(binary)
+	if infix {
+		fmt.Println(packet.infix())
+	}
+
+	if tree {
+		fmt.Print(packet.tree())
+	}
+}
+
+func printEncoded(sexpr string) {
+	packet, err := parseSExpr(sexpr)
 
 	if err != nil {
-		return 0, err
+		fmt.Println("failed to parse S-expression", err)
+		return
+	}
+
+	encoded, err := packet.encodeHex()
+
+	if err != nil {
+		fmt.Println("failed to encode", err)
+		return
 	}
 
-	return packet.evaluateExpression(), nil
+	fmt.Println(encoded)
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	encodeFlag := flag.String("encode", "", "print the hex for an S-expression, like \"(sum 1 (product 2 3))\", and exit")
+
+	infixFlag := flag.Bool("infix", false, "print the input as an infix expression")
+	treeFlag := flag.Bool("tree", false, "print the input as a tree, with versions and bit offsets")
+
+	flag.Parse()
+
+	if *encodeFlag != "" {
+		printEncoded(*encodeFlag)
+		return
+	}
+
 	data := FileLoader(filename)
 
 	start := time.Now()
@@ -76,4 +123,8 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d (%s) \n", answer2, time.Since(start))
+
+	if *infixFlag || *treeFlag {
+		printPacket(data, *infixFlag, *treeFlag)
+	}
 }
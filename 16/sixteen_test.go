@@ -1,7 +1,13 @@
 package main
 
 import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -10,11 +16,23 @@ func init() {
 	log.SetOutput(os.Stdout)
 }
 
+// parses the first packet, leaving the reader after it
+func parseHex(hexStr string) (*Packet, *BitReader, error) {
+	data, err := decodeHex(hexStr)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := newReader(data)
+	packet, err := parsePacket(reader)
+
+	return packet, reader, err
+}
+
 func TestPartOne1(t *testing.T) {
 	expected := 2021
-	binary, err := hexToBinary("D2FE28")
-
-	packet, tail, err := newPacket(binary)
+	packet, reader, err := parseHex("D2FE28")
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -28,16 +46,14 @@ func TestPartOne1(t *testing.T) {
 		t.Fail()
 	}
 
-	if tail != "000" {
-		t.Log("tail should be 000 but got: ", tail)
+	if reader.remaining() != 3 {
+		t.Log("there should be 3 bits left, but got:", reader.remaining())
 		t.Fail()
 	}
 }
 
 func TestPartOne2(t *testing.T) {
-	binary, err := hexToBinary("38006F45291200")
-
-	packet, tail, err := newPacket(binary)
+	packet, reader, err := parseHex("38006F45291200")
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -68,16 +84,14 @@ func TestPartOne2(t *testing.T) {
 		t.Fail()
 	}
 
-	if tail != "0000000" {
-		t.Log("tail should be 0000000 but got: ", tail)
+	if reader.remaining() != 7 {
+		t.Log("there should be 7 bits left, but got:", reader.remaining())
 		t.Fail()
 	}
 }
 
 func TestPartOne3(t *testing.T) {
-	binary, err := hexToBinary("EE00D40C823060")
-
-	packet, tail, err := newPacket(binary)
+	packet, reader, err := parseHex("EE00D40C823060")
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -108,16 +122,14 @@ func TestPartOne3(t *testing.T) {
 		t.Fail()
 	}
 
-	if tail != "00000" {
-		t.Log("tail should be 00000 but got: ", tail)
+	if reader.remaining() != 5 {
+		t.Log("there should be 5 bits left, but got:", reader.remaining())
 		t.Fail()
 	}
 }
 
 func TestPartOne_VersionSum1(t *testing.T) {
-	binary, err := hexToBinary("8A004A801A8002F478")
-
-	packet, _, err := newPacket(binary)
+	packet, _, err := parseHex("8A004A801A8002F478")
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -131,9 +143,7 @@ func TestPartOne_VersionSum1(t *testing.T) {
 }
 
 func TestPartOne_VersionSum2(t *testing.T) {
-	binary, err := hexToBinary("620080001611562C8802118E34")
-
-	packet, _, err := newPacket(binary)
+	packet, _, err := parseHex("620080001611562C8802118E34")
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -147,9 +157,7 @@ func TestPartOne_VersionSum2(t *testing.T) {
 }
 
 func TestPartOne_VersionSum3(t *testing.T) {
-	binary, err := hexToBinary("C0015000016115A2E0802F182340")
-
-	packet, _, err := newPacket(binary)
+	packet, _, err := parseHex("C0015000016115A2E0802F182340")
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -163,9 +171,7 @@ func TestPartOne_VersionSum3(t *testing.T) {
 }
 
 func TestPartOne_VersionSum4(t *testing.T) {
-	binary, err := hexToBinary("A0016C880162017C3686B18A3D4780")
-
-	packet, _, err := newPacket(binary)
+	packet, _, err := parseHex("A0016C880162017C3686B18A3D4780")
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -181,9 +187,7 @@ func TestPartOne_VersionSum4(t *testing.T) {
 func TestPartOne(t *testing.T) {
 	// the real data
 	content := FileLoader("input.txt")
-	binary, err := hexToBinary(content)
-
-	packet, _, err := newPacket(binary)
+	packet, _, err := parseHex(content)
 
 	if err != nil {
 		t.Log("error should be nil", err)
@@ -209,20 +213,364 @@ var hexes = map[string]int{
 
 func TestPartTwo(t *testing.T) {
 	for hex, expected := range hexes {
-		binary, err := hexToBinary(hex)
+		packet, _, err := parseHex(hex)
+
+		if err != nil {
+			t.Log("error should be nil", err)
+			t.Fail()
+		}
+
+		value, err := packet.evaluate()
+
+		if err != nil || !value.IsInt64() || value.Int64() != int64(expected) {
+			t.Logf("%s should evaluate to %d, got: %v (%v)", hex, expected, value, err)
+			t.Fail()
+		}
+	}
+}
+
+// "0101..." to hex, padded with zeros
+func bitsToHex(bits string) string {
+	for len(bits)%8 != 0 {
+		bits += "0"
+	}
+
+	data := make([]byte, len(bits)/8)
+
+	for i := range bits {
+		if bits[i] == '1' {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+
+	return hex.EncodeToString(data)
+}
+
+func TestBitReader(t *testing.T) {
+	reader := newReader([]byte{0b10110011, 0b01011100})
+
+	for _, want := range []struct{ n, val int }{{3, 0b101}, {7, 0b1001101}, {6, 0b011100}} {
+		val, err := reader.readInt(want.n)
+
+		if err != nil || val != want.val {
+			t.Logf("expected %b, got %b (%v)", want.val, val, err)
+			t.Fail()
+		}
+	}
+
+	_, err := reader.read(1)
+
+	var parseErr *ParseError
+
+	if !errors.As(err, &parseErr) || parseErr.Pos != 16 || !errors.Is(err, ErrOverRead) {
+		t.Logf("expected an over-read at bit 16, got %v", err)
+		t.Fail()
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		hex string
+		err error
+		pos int
+	}{
+		// the literal's second group is cut off
+		{"D2FE", ErrTruncatedLiteral, 16},
+		// sub-packets are longer than the data
+		{"38006F45", ErrOverRead, 22},
+		// an operator (10 bits long) around an 11 bit literal
+		{bitsToHex("000" + "000" + "0" + "000000000001010" + "000" + "100" + "00001"), ErrBadLengthType, 22},
+		// an operator with no sub-packets
+		{bitsToHex("000" + "000" + "1" + "00000000000"), ErrBadLengthType, 7},
+		// the padding isn't all zeros
+		{"D2FE29", ErrTrailingBits, 21},
+	}
+
+	for _, test := range tests {
+		_, err := parseTransmission(test.hex)
+
+		var parseErr *ParseError
+
+		if !errors.Is(err, test.err) || !errors.As(err, &parseErr) || parseErr.Pos != test.pos {
+			t.Logf("%s: expected %v at bit %d, got %v", test.hex, test.err, test.pos, err)
+			t.Fail()
+		}
+	}
+}
+
+// an operator with 2000 literals, 16 bits each
+func BenchmarkLargeTransmission(b *testing.B) {
+	var sb strings.Builder
+
+	sb.WriteString("000" + "000" + "1" + "11111010000")
+
+	for i := 0; i < 2000; i++ {
+		sb.WriteString("000" + "100" + "1" + "0101" + "0" + "1010")
+	}
+
+	hexStr := bitsToHex(sb.String())
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stdout)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		packet, err := parseTransmission(hexStr)
+
+		if err != nil || len(packet.packets) != 2000 {
+			b.Fatal("failed to parse", err)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for hexStr := range hexes {
+		packet, err := parseTransmission(hexStr)
+
+		if err != nil {
+			t.Log("error should be nil", err)
+			t.Fail()
+			continue
+		}
+
+		encoded, err := packet.encodeHex()
+
+		if err != nil {
+			t.Log("error should be nil", err)
+			t.Fail()
+			continue
+		}
+
+		decoded, err := parseTransmission(encoded)
+
+		if err != nil || !decoded.equal(packet) {
+			t.Logf("%s: round trip gave %s (%v)", hexStr, encoded, err)
+			t.Fail()
+		}
+	}
+
+	literal, _ := parseTransmission("D2FE28")
+
+	if encoded, _ := literal.encodeHex(); encoded != "D2FE28" {
+		t.Logf("expected D2FE28, got %s", encoded)
+		t.Fail()
+	}
+}
+
+func TestSExpr(t *testing.T) {
+	packet, err := parseSExpr("(sum:6 1 (product:3/0 2:7 3))")
+
+	if err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+		return
+	}
+
+	expected := "(sum:6/1 1:0 (product:3/0 2:7 3:0))"
+
+	if packet.sexpr() != expected {
+		t.Logf("expected %s, got %s", expected, packet.sexpr())
+		t.Fail()
+	}
+
+	encoded, _ := packet.encodeHex()
+	decoded, err := parseTransmission(encoded)
+
+	if err != nil || decoded.sexpr() != expected || decoded.infix() != "1 + 2 * 3" {
+		t.Logf("round trip gave %s (%v)", decoded.sexpr(), err)
+		t.Fail()
+	}
+
+	for _, bad := range []string{"(sum)", "(sum 1", "(nope 1)", "1 2", "(sum:8 1)", "(sum/2 1)", "-1"} {
+		if _, err := parseSExpr(bad); err == nil {
+			t.Logf("%s: expected an error", bad)
+			t.Fail()
+		}
+	}
+}
+
+func randomPacket(random *rand.Rand, depth int) *Packet {
+	version := random.Intn(8)
+
+	if depth == 0 || random.Intn(3) == 0 {
+		// mostly small numbers, but sometimes huge ones
+		return newLiteral(version, random.Intn(1<<uint(random.Intn(63))))
+	}
+
+	typeIds := []TypeId{TYPE_SUM, TYPE_PRODUCT, TYPE_MIN, TYPE_MAX, TYPE_GT, TYPE_LT, TYPE_EQ}
+	packets := []Packet{}
+
+	for i := 1 + random.Intn(4); i > 0; i-- {
+		packets = append(packets, *randomPacket(random, depth-1))
+	}
+
+	packet := newOperator(version, typeIds[random.Intn(len(typeIds))], packets...)
+	packet.lengthTypeId = LengthTypeId(random.Intn(2))
 
-		packet, _, err := newPacket(binary)
+	return packet
+}
+
+func TestRandomRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(47))
+
+	for i := 0; i < 200; i++ {
+		packet := randomPacket(random, 5)
+		encoded, err := packet.encodeHex()
 
 		if err != nil {
 			t.Log("error should be nil", err)
 			t.Fail()
+			continue
+		}
+
+		decoded, err := parseTransmission(encoded)
+
+		if err != nil || !decoded.equal(packet) {
+			t.Logf("%s: round trip failed (%v)", packet.sexpr(), err)
+			t.Fail()
+		}
+
+		// and through an S-expression
+		if parsed, err := parseSExpr(packet.sexpr()); err != nil || !parsed.equal(packet) {
+			t.Logf("%s: S-expression round trip failed (%v)", packet.sexpr(), err)
+			t.Fail()
+		}
+	}
+}
+
+// anything the parser accepts encodes back to the same tree
+func FuzzRoundTrip(f *testing.F) {
+	for hexStr := range hexes {
+		f.Add(hexStr)
+	}
+
+	f.Fuzz(func(t *testing.T, hexStr string) {
+		packet, err := parseTransmission(hexStr)
+
+		if err != nil {
+			return
+		}
+
+		encoded, err := packet.encodeHex()
+
+		if err != nil {
+			t.Fatal("can't encode", packet.sexpr(), err)
+		}
+
+		decoded, err := parseTransmission(encoded)
+
+		if err != nil || !decoded.equal(packet) {
+			t.Fatalf("%s: round trip failed (%v)", hexStr, err)
+		}
+	})
+}
+
+func TestEvaluateBig(t *testing.T) {
+	// 2^60 * 2^60 would overflow an int
+	packet, _ := parseSExpr("(product 1152921504606846976 1152921504606846976 3)")
+	value, err := packet.evaluate()
+
+	// 3 * 2^120
+	expected := new(big.Int).Lsh(big.NewInt(3), 120)
+
+	if err != nil || value.Cmp(expected) != 0 {
+		t.Logf("expected %s, got %v (%v)", expected, value, err)
+		t.Fail()
+	}
+
+	encoded, _ := packet.encodeHex()
+
+	if _, err := PartTwo(encoded); err == nil {
+		t.Log("PartTwo should fail when the answer doesn't fit in an int")
+		t.Fail()
+	}
+}
+
+func TestArity(t *testing.T) {
+	tests := map[string]bool{
+		"(gt 1 2)":            true,
+		"(gt 1 2 3)":          false,
+		"(eq 1)":              false,
+		"(sum 1 (lt 4))":      false,
+		"(min 5)":             true,
+		"(max 1 2 3 4 5 6)":   true,
+		"(product (sum 1) 2)": true,
+	}
+
+	for sexpr, valid := range tests {
+		packet, _ := parseSExpr(sexpr)
+		_, err := packet.evaluate()
+
+		if valid != (err == nil) {
+			t.Logf("%s: valid should be %v, got error %v", sexpr, valid, err)
+			t.Fail()
+		}
+
+		if !valid && !errors.Is(err, ErrArity) {
+			t.Logf("%s: expected ErrArity, got %v", sexpr, err)
+			t.Fail()
 		}
+	}
+
+	// the error points at the bad operator, not the root
+	packet, _ := parseSExpr("(sum 1 (lt 4))")
+	encoded, _ := packet.encodeHex()
+	decoded, _ := parseTransmission(encoded)
+	_, err := decoded.evaluate()
+
+	var parseErr *ParseError
+
+	if !errors.As(err, &parseErr) || parseErr.Pos != decoded.packets[1].offset {
+		t.Logf("expected an error at bit %d, got %v", decoded.packets[1].offset, err)
+		t.Fail()
+	}
+}
+
+func TestInfix(t *testing.T) {
+	tests := map[string]string{
+		"(gt (max 3 (product 7 2)) 5)":  "max(3, 7 * 2) > 5",
+		"(product (sum 1 2) 3)":         "(1 + 2) * 3",
+		"(sum (product 1 2) (sum 3 4))": "1 * 2 + 3 + 4",
+		"(eq (lt 1 2) 1)":               "(1 < 2) == 1",
+		"(sum (gt 1 2) 3)":              "(1 > 2) + 3",
+		"(product 5)":                   "product(5)",
+		"(gt 1 2 3)":                    "gt(1, 2, 3)",
+		"(min 1 (sum 2 3))":             "min(1, 2 + 3)",
+		"(eq (sum 1 3) (product 2 2))":  "1 + 3 == 2 * 2",
+	}
 
-		expr := packet.evaluateExpression()
+	for sexpr, expected := range tests {
+		packet, _ := parseSExpr(sexpr)
 
-		if expr != expected {
-			t.Logf("packet version sum should be %d, got: %d", expected, expr)
+		if packet.infix() != expected {
+			t.Logf("%s: expected %s, got %s", sexpr, expected, packet.infix())
 			t.Fail()
 		}
 	}
 }
+
+func TestTree(t *testing.T) {
+	packet, _ := parseTransmission("9C0141080250320F1802104A08")
+
+	expected := strings.Join([]string{
+		"eq (v4, bit 0)",
+		"  sum (v2, bit 22)",
+		"    1 (v2, bit 40)",
+		"    3 (v4, bit 51)",
+		"  product (v6, bit 62)",
+		"    2 (v0, bit 80)",
+		"    2 (v2, bit 91)",
+		"",
+	}, "\n")
+
+	if packet.tree() != expected {
+		t.Logf("expected:\n%s\ngot:\n%s", expected, packet.tree())
+		t.Fail()
+	}
+
+	if packet.infix() != "1 + 3 == 2 * 2" {
+		t.Logf("unexpected infix: %s", packet.infix())
+		t.Fail()
+	}
+}
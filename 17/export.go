@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bozdoz/advent-of-code-2021/types"
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+const (
+	// anything bigger is almost certainly a mistake
+	maxImagePixels = 1 << 24
+	// pixels per velocity in the hit map
+	hitScale = 4
+	// longest side of the svg, in pixels
+	svgSize = 800
+)
+
+var (
+	missColor = color.RGBA{20, 20, 30, 255}
+	axisColor = color.RGBA{70, 70, 90, 255}
+	// early hits are blue, late ones are red
+	stepRamp = utils.ColorRamp{
+		{40, 80, 255, 255},
+		{255, 220, 0, 255},
+		{255, 40, 40, 255},
+	}
+)
+
+// Flight is where a probe went, from the launcher until it can't hit
+// the target anymore
+type Flight struct {
+	positions  []types.Vector[int]
+	velocities []types.Vector[int]
+	// the first step in the target, or -1
+	hitStep int
+}
+
+// stopped for good: it'll never move again
+func (probe *Probe) stopped() bool {
+	return probe.physics.gravity == 0 && probe.velocity.X == 0 && probe.velocity.Y == 0
+}
+
+func (probe *Probe) flight(target *Target) Flight {
+	flight := Flight{hitStep: -1}
+
+	for {
+		flight.positions = append(flight.positions, probe.position)
+		flight.velocities = append(flight.velocities, probe.velocity)
+
+		if flight.hitStep == -1 && probe.steps > 0 && probe.isInTarget(target) {
+			flight.hitStep = probe.steps
+		}
+
+		if probe.missedTarget(target) || probe.stopped() {
+			return flight
+		}
+
+		probe.tick()
+	}
+}
+
+// step,x,y,vx,vy,in_target: one row per step, including the launch
+func (flight Flight) writeCSV(writer io.Writer, target *Target) error {
+	w := csv.NewWriter(writer)
+
+	if err := w.Write([]string{"step", "x", "y", "vx", "vy", "in_target"}); err != nil {
+		return err
+	}
+
+	for step, pos := range flight.positions {
+		vel := flight.velocities[step]
+		record := []string{
+			fmt.Sprint(step),
+			fmt.Sprint(pos.X),
+			fmt.Sprint(pos.Y),
+			fmt.Sprint(vel.X),
+			fmt.Sprint(vel.Y),
+			fmt.Sprint(step > 0 && target.contains(pos)),
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+// the flight over the target box, with y going up like in the puzzle
+func (flight Flight) svg(target *Target) string {
+	xmin, xmax := utils.MinInt(target.xmin, 0), utils.MaxInt(target.xmax, 0)
+	ymin, ymax := utils.MinInt(target.ymin, 0), utils.MaxInt(target.ymax, 0)
+
+	for _, pos := range flight.positions {
+		xmin, xmax = utils.MinInt(xmin, pos.X), utils.MaxInt(xmax, pos.X)
+		ymin, ymax = utils.MinInt(ymin, pos.Y), utils.MaxInt(ymax, pos.Y)
+	}
+
+	// a step of margin all around
+	xmin, xmax, ymin, ymax = xmin-1, xmax+1, ymin-1, ymax+1
+	width, height := xmax-xmin, ymax-ymin
+	scale := float64(svgSize) / float64(utils.MaxInt(width, height))
+
+	var sb strings.Builder
+
+	// flipping y: everything is drawn at -y
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="%d %d %d %d">`+"\n",
+		int(float64(width)*scale), int(float64(height)*scale), xmin, -ymax, width, height)
+	fmt.Fprintf(&sb, `  <rect x="%d" y="%d" width="%d" height="%d" fill="white"/>`+"\n", xmin, -ymax, width, height)
+	fmt.Fprintf(&sb, `  <rect x="%d" y="%d" width="%d" height="%d" fill="#8fd18f" fill-opacity="0.5" stroke="green" stroke-width="1" vector-effect="non-scaling-stroke"/>`+"\n",
+		target.xmin, -target.ymax, target.xmax-target.xmin, target.ymax-target.ymin)
+
+	points := make([]string, len(flight.positions))
+
+	for i, pos := range flight.positions {
+		points[i] = fmt.Sprintf("%d,%d", pos.X, -pos.Y)
+	}
+
+	fmt.Fprintf(&sb, `  <polyline points="%s" fill="none" stroke="#3050ff" stroke-width="1.5" vector-effect="non-scaling-stroke"/>`+"\n",
+		strings.Join(points, " "))
+
+	radius := 3 / scale
+
+	for step, pos := range flight.positions {
+		fill := "#3050ff"
+
+		if step == 0 {
+			fill = "black"
+		} else if step == flight.hitStep {
+			fill = "red"
+		}
+
+		fmt.Fprintf(&sb, `  <circle cx="%d" cy="%d" r="%.3g" fill="%s"/>`+"\n", pos.X, -pos.Y, radius, fill)
+	}
+
+	sb.WriteString("</svg>\n")
+
+	return sb.String()
+}
+
+// .csv or .svg
+func (flight Flight) export(filename string, target *Target) error {
+	switch filepath.Ext(filename) {
+	case ".csv":
+		file, err := os.Create(filename)
+
+		if err != nil {
+			return err
+		}
+
+		err = flight.writeCSV(file, target)
+
+		// a failed close can mean the rows never made it to disk
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+
+		return err
+	case ".svg":
+		return os.WriteFile(filename, []byte(flight.svg(target)), 0644)
+	}
+
+	return fmt.Errorf("can't export a trajectory to %q: use .csv or .svg", filename)
+}
+
+// one square per velocity, vx across and vy up: hits are coloured by
+// the step they first hit on, and the axes are drawn in to get your
+// bearings
+func (target *Target) hitMap(physics Physics) (*image.RGBA, error) {
+	hits := []Hit{}
+	// always showing the launcher's velocity (0, 0)
+	vxmin, vxmax, vymin, vymax, lastStep := 0, 0, 0, 0, 1
+
+	err := target.practice(physics, func(hit Hit) {
+		hits = append(hits, hit)
+		vxmin, vxmax = utils.MinInt(vxmin, hit.velocity.X), utils.MaxInt(vxmax, hit.velocity.X)
+		vymin, vymax = utils.MinInt(vymin, hit.velocity.Y), utils.MaxInt(vymax, hit.velocity.Y)
+		lastStep = utils.MaxInt(lastStep, hit.step)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a velocity of margin all around
+	vxmin, vxmax, vymin, vymax = vxmin-1, vxmax+1, vymin-1, vymax+1
+	width, height := vxmax-vxmin+1, vymax-vymin+1
+
+	if width*height*hitScale*hitScale > maxImagePixels {
+		return nil, errors.New("too many velocities for an image")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width*hitScale, height*hitScale))
+
+	fill := func(vx, vy int, c color.RGBA) {
+		x, y := (vx-vxmin)*hitScale, (vymax-vy)*hitScale
+
+		for dy := 0; dy < hitScale; dy++ {
+			for dx := 0; dx < hitScale; dx++ {
+				img.SetRGBA(x+dx, y+dy, c)
+			}
+		}
+	}
+
+	for vy := vymin; vy <= vymax; vy++ {
+		for vx := vxmin; vx <= vxmax; vx++ {
+			if vx == 0 || vy == 0 {
+				fill(vx, vy, axisColor)
+			} else {
+				fill(vx, vy, missColor)
+			}
+		}
+	}
+
+	for _, hit := range hits {
+		t := 0.0
+
+		if lastStep > 1 {
+			t = float64(hit.step-1) / float64(lastStep-1)
+		}
+
+		fill(hit.velocity.X, hit.velocity.Y, stepRamp.At(t))
+	}
+
+	return img, nil
+}
+
+// a .png or .pgm hit map
+func (target *Target) exportHitMap(filename string, physics Physics) error {
+	img, err := target.hitMap(physics)
+
+	if err != nil {
+		return err
+	}
+
+	return utils.SaveImage(filename, img)
+}
@@ -1,31 +1,52 @@
 package main
 
-import "github.com/bozdoz/advent-of-code-2021/types"
+import (
+	"github.com/bozdoz/advent-of-code-2021/types"
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
 
-type Probe struct {
-	position, velocity, forceVec types.Vector[int]
-	maxHeight                    int
+// Physics is how much a probe slows down every step: gravity pulls
+// y down, and drag pulls x towards 0 (but never past it)
+type Physics struct {
+	gravity, drag int
 }
 
-var forceVec = types.NewVector(-1, -1)
+var standardPhysics = Physics{gravity: 1, drag: 1}
+
+type Probe struct {
+	position, velocity types.Vector[int]
+	physics            Physics
+	maxHeight          int
+	// steps taken so far
+	steps int
+}
 
 func newProbe(px, py, vx, vy int) *Probe {
+	return newProbeWithPhysics(px, py, vx, vy, standardPhysics)
+}
+
+func newProbeWithPhysics(px, py, vx, vy int, physics Physics) *Probe {
 	return &Probe{
 		position: types.NewVector(px, py),
 		velocity: types.NewVector(vx, vy),
-		forceVec: forceVec,
+		physics:  physics,
 	}
 }
 
 // advance to next step
 func (probe *Probe) tick() {
 	probe.position = probe.position.Add(probe.velocity)
+	probe.steps++
 
 	// "x velocity...does not change if it is already 0"
-	if probe.velocity.X == 0 {
-		probe.forceVec.X = 0
+	switch vx := probe.velocity.X; {
+	case vx > 0:
+		probe.velocity.X = utils.MaxInt(vx-probe.physics.drag, 0)
+	case vx < 0:
+		probe.velocity.X = utils.MinInt(vx+probe.physics.drag, 0)
 	}
-	probe.velocity = probe.velocity.Add(probe.forceVec)
+
+	probe.velocity.Y -= probe.physics.gravity
 
 	if probe.position.Y > probe.maxHeight {
 		probe.maxHeight = probe.position.Y
@@ -49,6 +70,20 @@ func (probe *Probe) isInTarget(target *Target) bool {
 	return target.contains(probe.position)
 }
 
+// past the target and heading away from it (or stopped), on either axis
 func (probe *Probe) missedTarget(target *Target) bool {
-	return probe.position.X > target.xmax || probe.position.Y < target.ymin
+	pos, vel := probe.position, probe.velocity
+
+	// x only ever slows down, and never turns around
+	if (pos.X > target.xmax && vel.X >= 0) || (pos.X < target.xmin && vel.X <= 0) {
+		return true
+	}
+
+	// y never speeds back up
+	if pos.Y < target.ymin && vel.Y <= 0 {
+		return true
+	}
+
+	// and without gravity, never comes back down
+	return probe.physics.gravity == 0 && pos.Y > target.ymax && vel.Y >= 0
 }
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 
+	"github.com/bozdoz/advent-of-code-2021/types"
 	"github.com/bozdoz/advent-of-code-2021/utils"
 )
 
@@ -25,13 +27,13 @@ func PartOne(content string) (output int, err error) {
 
 	maxHeight := 0
 
-	target.practice(func(probe *Probe, success bool) {
-		if success && probe.maxHeight > maxHeight {
-			maxHeight = probe.maxHeight
+	err = target.practice(standardPhysics, func(hit Hit) {
+		if hit.maxHeight > maxHeight {
+			maxHeight = hit.maxHeight
 		}
 	})
 
-	return maxHeight, nil
+	return maxHeight, err
 }
 
 func PartTwo(content string) (output int, err error) {
@@ -41,19 +43,82 @@ func PartTwo(content string) (output int, err error) {
 
 	hitCount := 0
 
-	target.practice(func(_ *Probe, success bool) {
-		if success {
-			hitCount++
-		}
+	err = target.practice(standardPhysics, func(_ Hit) {
+		hitCount++
+	})
+
+	return hitCount, err
+}
+
+// both parts at once, with different physics
+func printPractice(target *Target, physics Physics) {
+	maxHeight, hitCount := 0, 0
+
+	err := target.practice(physics, func(hit Hit) {
+		maxHeight = utils.MaxInt(maxHeight, hit.maxHeight)
+		hitCount++
 	})
 
-	return hitCount, nil
+	if err != nil {
+		fmt.Println("failed to practice", err)
+		return
+	}
+
+	fmt.Printf("With gravity %d and drag %d: max height %d, %d hits \n", physics.gravity, physics.drag, maxHeight, hitCount)
+}
+
+func parseVelocity(str string) (vel types.Vector[int], err error) {
+	_, err = fmt.Sscanf(str, "%d,%d", &vel.X, &vel.Y)
+
+	return
+}
+
+// one launch's trajectory, and/or the hit map
+func exportPractice(target *Target, physics Physics, launch, trajectoryFile, hitMapFile string) {
+	if trajectoryFile != "" {
+		vel, err := parseVelocity(launch)
+
+		if err != nil {
+			fmt.Println("failed to parse launch velocity", err)
+			return
+		}
+
+		flight := newProbeWithPhysics(0, 0, vel.X, vel.Y, physics).flight(target)
+
+		if err := flight.export(trajectoryFile, target); err != nil {
+			fmt.Println("failed to export trajectory", err)
+			return
+		}
+
+		if flight.hitStep == -1 {
+			fmt.Printf("Wrote %s: %d steps, missed \n", trajectoryFile, len(flight.positions)-1)
+		} else {
+			fmt.Printf("Wrote %s: %d steps, first hit on step %d \n", trajectoryFile, len(flight.positions)-1, flight.hitStep)
+		}
+	}
+
+	if hitMapFile != "" {
+		if err := target.exportHitMap(hitMapFile, physics); err != nil {
+			fmt.Println("failed to export hit map", err)
+			return
+		}
+
+		fmt.Printf("Wrote %s \n", hitMapFile)
+	}
 }
 
 func main() {
 	// safe to assume
 	filename := "input.txt"
 
+	gravityFlag := flag.Int("gravity", standardPhysics.gravity, "how much y velocity drops each step")
+	dragFlag := flag.Int("drag", standardPhysics.drag, "how much x velocity drops (towards 0) each step")
+	launchFlag := flag.String("launch", "6,9", "launch velocity for -export, as \"vx,vy\"")
+	exportFlag := flag.String("export", "", "write the -launch trajectory to a .csv or .svg file")
+	hitMapFlag := flag.String("hitmap", "", "write every velocity that hits to a .png or .pgm image")
+
+	flag.Parse()
+
 	data := FileLoader(filename)
 
 	answer, err := PartOne(data)
@@ -73,4 +138,22 @@ func main() {
 	}
 
 	fmt.Printf("Part Two: %d \n", answer2)
+
+	physics := Physics{gravity: *gravityFlag, drag: *dragFlag}
+
+	exporting := *exportFlag != "" || *hitMapFlag != ""
+
+	if physics == standardPhysics && !exporting {
+		return
+	}
+
+	target := parseTarget(data)
+
+	if physics != standardPhysics {
+		printPractice(target, physics)
+	}
+
+	if exporting {
+		exportPractice(target, physics, *launchFlag, *exportFlag, *hitMapFlag)
+	}
 }
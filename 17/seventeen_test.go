@@ -1,10 +1,15 @@
 package main
 
 import (
+	"errors"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bozdoz/advent-of-code-2021/types"
+	"github.com/bozdoz/advent-of-code-2021/utils"
 )
 
 // show log output for tests only
@@ -182,3 +187,245 @@ func TestPartTwoFailedExample(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSteps(t *testing.T) {
+	// 7 with drag: 7, 13, 18, 22, 25, 27, 28, 28...
+	xs := xSteps(7, 20, 30, 1)
+	expected := []Steps{{4, forever}}
+
+	if len(xs) != 1 || xs[0] != expected[0] {
+		t.Logf("Answer should be %v, but got %v", expected, xs)
+		t.Fail()
+	}
+
+	// 4 with gravity: 4, 7, 9, 10, 10, 9, 7, 4, 0, -5...
+	ys := ySteps(4, 5, 9, 1)
+	expected = []Steps{{2, 3}, {6, 7}}
+
+	if len(ys) != 2 || ys[0] != expected[0] || ys[1] != expected[1] {
+		t.Logf("Answer should be %v, but got %v", expected, ys)
+		t.Fail()
+	}
+
+	// the top of the arc joins both ways into one stretch
+	ys = ySteps(4, 9, 10, 1)
+	expected = []Steps{{3, 6}}
+
+	if len(ys) != 1 || ys[0] != expected[0] {
+		t.Logf("Answer should be %v, but got %v", expected, ys)
+		t.Fail()
+	}
+}
+
+// every hit, from simulating every velocity in a (big enough) box
+func simulate(target *Target, physics Physics, box int) map[types.Vector[int]]Hit {
+	hits := map[types.Vector[int]]Hit{}
+
+	for vy := -box; vy <= box; vy++ {
+		for vx := -box; vx <= box; vx++ {
+			probe := newProbeWithPhysics(0, 0, vx, vy, physics)
+
+			if probe.isLaunchSuccessful(target) {
+				hits[types.NewVector(vx, vy)] = Hit{types.NewVector(vx, vy), probe.steps, probe.maxHeight}
+			}
+		}
+	}
+
+	return hits
+}
+
+func TestPracticeMatchesSimulation(t *testing.T) {
+	random := rand.New(rand.NewSource(17))
+
+	for i := 0; i < 200; i++ {
+		x1, x2 := random.Intn(31)-15, random.Intn(31)-15
+		y1, y2 := random.Intn(31)-15, random.Intn(31)-15
+		target := &Target{utils.MinInt(x1, x2), utils.MaxInt(x1, x2), utils.MinInt(y1, y2), utils.MaxInt(y1, y2)}
+		physics := Physics{gravity: random.Intn(4), drag: random.Intn(4)}
+
+		hits := map[types.Vector[int]]Hit{}
+		err := target.practice(physics, func(hit Hit) {
+			hits[hit.velocity] = hit
+		})
+
+		if errors.Is(err, ErrUnbounded) {
+			continue
+		}
+
+		if err != nil {
+			t.Logf("%v %+v: expected no error, got %v", *target, physics, err)
+			t.Fail()
+			continue
+		}
+
+		simulated := map[types.Vector[int]]Hit{}
+
+		for _, hit := range simulate(target, physics, 80) {
+			simulated[hit.velocity] = hit
+		}
+
+		if len(hits) != len(simulated) {
+			t.Logf("%v %+v: expected %d hits, got %d", *target, physics, len(simulated), len(hits))
+			t.Fail()
+			continue
+		}
+
+		for velocity, hit := range simulated {
+			if hits[velocity] != hit {
+				t.Logf("%v %+v: expected %+v, got %+v", *target, physics, hit, hits[velocity])
+				t.Fail()
+				break
+			}
+		}
+	}
+}
+
+func TestTargetAbove(t *testing.T) {
+	target := &Target{-30, -20, 5, 10}
+	hitCount, maxHeight := 0, 0
+
+	err := target.practice(standardPhysics, func(hit Hit) {
+		hitCount++
+		maxHeight = utils.MaxInt(maxHeight, hit.maxHeight)
+
+		if hit.velocity.X >= 0 || hit.velocity.Y <= 0 {
+			t.Logf("%v can't reach a target above and to the left", hit.velocity)
+			t.Fail()
+		}
+	})
+
+	if err != nil || hitCount == 0 {
+		t.Logf("expected hits, got %d (%v)", hitCount, err)
+		t.Fail()
+	}
+
+	// straight up at 10 tops out at 55, on the way to falling past
+	if maxHeight > 55 {
+		t.Logf("max height should be at most 55, got %d", maxHeight)
+		t.Fail()
+	}
+}
+
+func TestUnbounded(t *testing.T) {
+	// anything thrown straight up lands back in it
+	target := &Target{-2, 2, -2, 2}
+
+	if err := target.practice(standardPhysics, func(Hit) {}); !errors.Is(err, ErrUnbounded) {
+		t.Logf("expected ErrUnbounded, got %v", err)
+		t.Fail()
+	}
+
+	// without drag, x never stops in it
+	target = &Target{5, 10, -2, 2}
+	hitCount := 0
+
+	err := target.practice(Physics{gravity: 1}, func(Hit) {
+		hitCount++
+	})
+
+	if err != nil || hitCount == 0 {
+		t.Logf("expected some hits, got %d (%v)", hitCount, err)
+		t.Fail()
+	}
+
+	if err := target.practice(Physics{drag: -1}, func(Hit) {}); err == nil {
+		t.Log("negative drag should be an error")
+		t.Fail()
+	}
+}
+
+func TestFlight(t *testing.T) {
+	target := &Target{20, 30, -10, -5}
+	flight := newProbe(0, 0, 7, 2).flight(target)
+
+	if flight.hitStep != 7 {
+		t.Logf("Answer should be %v, but got %v", 7, flight.hitStep)
+		t.Fail()
+	}
+
+	// "(28,-7)" is in the target, and it keeps going until it's below it
+	last := flight.positions[len(flight.positions)-1]
+
+	if !flight.positions[7].IsEqualTo(types.NewVector(28, -7)) || last.Y >= target.ymin {
+		t.Logf("unexpected flight: %v", flight.positions)
+		t.Fail()
+	}
+
+	var sb strings.Builder
+
+	if err := flight.writeCSV(&sb, target); err != nil {
+		t.Log("Expected no error, got:", err)
+		t.Fail()
+	}
+
+	lines := strings.Split(sb.String(), "\n")
+
+	if lines[0] != "step,x,y,vx,vy,in_target" || lines[1] != "0,0,0,7,2,false" || lines[8] != "7,28,-7,0,-5,true" {
+		t.Logf("unexpected csv:\n%s", sb.String())
+		t.Fail()
+	}
+
+	svg := flight.svg(target)
+
+	// the target, flipped so y goes up
+	if !strings.Contains(svg, `<rect x="20" y="5" width="10" height="5"`) || !strings.Contains(svg, `<circle cx="28" cy="7"`) {
+		t.Logf("unexpected svg:\n%s", svg)
+		t.Fail()
+	}
+}
+
+func TestHitMap(t *testing.T) {
+	target := parseTarget(FileLoader("example.txt"))
+	img, err := target.hitMap(standardPhysics)
+
+	if err != nil {
+		t.Log("Expected no error, got:", err)
+		t.Fail()
+		return
+	}
+
+	hitCount := 0
+	bounds := img.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += hitScale {
+		for x := bounds.Min.X; x < bounds.Max.X; x += hitScale {
+			if c := img.RGBAAt(x, y); c != missColor && c != axisColor {
+				hitCount++
+			}
+		}
+	}
+
+	if hitCount != 112 {
+		t.Logf("Expected %v hits in the image, got %v", 112, hitCount)
+		t.Fail()
+	}
+
+	// vx goes from -1 to 31 (with a margin), and vy from -11 to 10
+	if bounds.Dx() != 33*hitScale || bounds.Dy() != 22*hitScale {
+		t.Logf("unexpected size: %v", bounds)
+		t.Fail()
+	}
+}
+
+func TestExportTrajectory(t *testing.T) {
+	target := &Target{20, 30, -10, -5}
+	flight := newProbe(0, 0, 7, 2).flight(target)
+	filename := filepath.Join(t.TempDir(), "flight.csv")
+
+	if err := flight.export(filename, target); err != nil {
+		t.Log("Expected no error, got:", err)
+		t.Fail()
+	}
+
+	data, _ := os.ReadFile(filename)
+
+	if !strings.HasPrefix(string(data), "step,x,y,vx,vy,in_target\n") {
+		t.Logf("unexpected csv:\n%s", data)
+		t.Fail()
+	}
+
+	if err := flight.export(filepath.Join(t.TempDir(), "missing", "flight.csv"), target); err == nil {
+		t.Log("expected an error for a missing directory")
+		t.Fail()
+	}
+}
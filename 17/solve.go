@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bozdoz/advent-of-code-2021/types"
+	"github.com/bozdoz/advent-of-code-2021/utils"
+)
+
+var ErrUnbounded = errors.New("infinitely many velocities hit the target")
+
+// a probe that never leaves a range stays there until forever
+const forever = math.MaxInt
+
+// Steps is when (counting from 1) a probe is within a range on one axis
+type Steps struct {
+	from, to int
+}
+
+// appends, merging with the last one if they touch
+func addSteps(steps []Steps, next Steps) []Steps {
+	if n := len(steps); n > 0 && steps[n-1].to >= next.from-1 {
+		steps[n-1].to = utils.MaxInt(steps[n-1].to, next.to)
+
+		return steps
+	}
+
+	return append(steps, next)
+}
+
+// the steps from..to where pos is within lo..hi, when pos never goes
+// down; if to is forever, pos has to keep going up
+func addRising(steps []Steps, pos func(n int) int, from, to, lo, hi int) []Steps {
+	// far enough that it's past hi
+	if to == forever {
+		to = from
+
+		for pos(to) <= hi {
+			to = from + (to-from+1)*2
+		}
+	}
+
+	first := from + sort.Search(to-from+1, func(i int) bool {
+		return pos(from+i) >= lo
+	})
+	last := from + sort.Search(to-from+1, func(i int) bool {
+		return pos(from+i) > hi
+	}) - 1
+
+	if first > last {
+		return steps
+	}
+
+	return addSteps(steps, Steps{first, last})
+}
+
+// the same, when pos never goes up
+func addFalling(steps []Steps, pos func(n int) int, from, to, lo, hi int) []Steps {
+	negated := func(n int) int {
+		return -pos(n)
+	}
+
+	return addRising(steps, negated, from, to, -hi, -lo)
+}
+
+// moving at v, how many steps until drag (or gravity) uses it up
+func stepsUntilStopped(v, slowdown int) int {
+	return (v + slowdown - 1) / slowdown
+}
+
+// x moves one way until drag stops it, so it's in range for (at most)
+// one stretch of steps
+func xSteps(v, lo, hi, drag int) []Steps {
+	// mirrored, so it's always moving right
+	if v < 0 {
+		return xSteps(-v, -hi, -lo, drag)
+	}
+
+	if v == 0 {
+		if lo <= 0 && 0 <= hi {
+			return []Steps{{1, forever}}
+		}
+
+		return nil
+	}
+
+	if drag == 0 {
+		return addRising(nil, func(n int) int {
+			return n * v
+		}, 1, forever, lo, hi)
+	}
+
+	stop := stepsUntilStopped(v, drag)
+	pos := func(n int) int {
+		n = utils.MinInt(n, stop)
+
+		return n*v - drag*n*(n-1)/2
+	}
+
+	steps := addRising(nil, pos, 1, stop, lo, hi)
+
+	// and then it stays put
+	if final := pos(stop); lo <= final && final <= hi {
+		steps = addSteps(steps, Steps{stop + 1, forever})
+	}
+
+	return steps
+}
+
+func yAt(v, gravity, n int) int {
+	return n*v - gravity*n*(n-1)/2
+}
+
+// y goes up until gravity stops it, then down forever; on the way up
+// and on the way down are separate stretches of steps
+func ySteps(v, lo, hi, gravity int) []Steps {
+	pos := func(n int) int {
+		return yAt(v, gravity, n)
+	}
+
+	if gravity == 0 {
+		switch {
+		case v > 0:
+			return addRising(nil, pos, 1, forever, lo, hi)
+		case v < 0:
+			return addFalling(nil, pos, 1, forever, lo, hi)
+		case lo <= 0 && 0 <= hi:
+			return []Steps{{1, forever}}
+		}
+
+		return nil
+	}
+
+	peak := 0
+
+	if v > 0 {
+		peak = stepsUntilStopped(v, gravity)
+	}
+
+	steps := addRising(nil, pos, 1, peak, lo, hi)
+
+	return addFalling(steps, pos, peak+1, forever, lo, hi)
+}
+
+// the first step both axes are in range
+func firstCommonStep(xs, ys []Steps) (first int, ok bool) {
+	first = forever
+
+	for _, x := range xs {
+		for _, y := range ys {
+			from := utils.MaxInt(x.from, y.from)
+
+			if from <= utils.MinInt(x.to, y.to) && from < first {
+				first, ok = from, true
+			}
+		}
+	}
+
+	return
+}
+
+// Hit is a velocity that hits the target, and when
+type Hit struct {
+	velocity types.Vector[int]
+	// the first step it's in the target
+	step int
+	// the highest it got, up until that step
+	maxHeight int
+}
+
+func maxHeight(vy, gravity, step int) int {
+	if vy <= 0 {
+		return 0
+	}
+
+	if gravity > 0 {
+		step = utils.MinInt(step, stepsUntilStopped(vy, gravity))
+	}
+
+	return yAt(vy, gravity, step)
+}
+
+type xCandidate struct {
+	vx    int
+	steps []Steps
+}
+
+// every velocity that hits the target, without simulating any of them:
+// each axis gives the steps it's in range, and a hit is where they overlap
+func (target *Target) practice(physics Physics, forEach func(hit Hit)) error {
+	if physics.gravity < 0 || physics.drag < 0 {
+		return fmt.Errorf("gravity and drag can't be negative: %+v", physics)
+	}
+
+	if target.xmin > target.xmax || target.ymin > target.ymax {
+		return fmt.Errorf("target is inside out: %+v", *target)
+	}
+
+	// anything faster passes the target on its first step
+	xs := []xCandidate{}
+	longest, endless := 0, false
+
+	for vx := utils.MinInt(target.xmin, 0); vx <= utils.MaxInt(target.xmax, 0); vx++ {
+		steps := xSteps(vx, target.xmin, target.xmax, physics.drag)
+
+		if len(steps) == 0 {
+			continue
+		}
+
+		xs = append(xs, xCandidate{vx, steps})
+
+		if last := steps[len(steps)-1].to; last == forever {
+			endless = true
+		} else {
+			longest = utils.MaxInt(longest, last)
+		}
+	}
+
+	if len(xs) == 0 {
+		return nil
+	}
+
+	vyMin, vyMax := utils.MinInt(target.ymin, 0), utils.MaxInt(target.ymax, 0)
+
+	if physics.gravity > 0 {
+		// slower can't get up to the target
+		vyMin = utils.MinInt(target.ymin, 1)
+
+		if target.ymin > 0 || target.ymax < 0 {
+			// faster jumps right over it, on the way up and on the way down
+			vyMax = physics.gravity * utils.MaxInt(utils.Abs(target.ymin), utils.Abs(target.ymax))
+		} else if endless {
+			// it comes back to 0 (and so the target) for any multiple of gravity
+			return ErrUnbounded
+		} else {
+			// faster is still above the target by the time x leaves it
+			vyMax = target.ymax + physics.gravity*longest
+		}
+	}
+
+	for vy := vyMin; vy <= vyMax; vy++ {
+		ys := ySteps(vy, target.ymin, target.ymax, physics.gravity)
+
+		if len(ys) == 0 {
+			continue
+		}
+
+		for _, x := range xs {
+			if step, ok := firstCommonStep(x.steps, ys); ok {
+				forEach(Hit{
+					velocity:  types.NewVector(x.vx, vy),
+					step:      step,
+					maxHeight: maxHeight(vy, physics.gravity, step),
+				})
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// a ColorRamp maps 0..1 onto colours, blending evenly between each stop
+type ColorRamp []color.RGBA
+
+var (
+	GrayRamp = ColorRamp{
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+	}
+	// black -> red -> yellow -> white
+	HeatRamp = ColorRamp{
+		{0, 0, 0, 255},
+		{200, 0, 0, 255},
+		{255, 220, 0, 255},
+		{255, 255, 255, 255},
+	}
+)
+
+// colour at t, where t is clamped to 0..1
+func (ramp ColorRamp) At(t float64) color.RGBA {
+	if len(ramp) == 0 {
+		panic("ColorRamp needs at least one colour")
+	}
+
+	if len(ramp) == 1 || t <= 0 || math.IsNaN(t) {
+		return ramp[0]
+	}
+
+	if t >= 1 {
+		return ramp[len(ramp)-1]
+	}
+
+	pos := t * float64(len(ramp)-1)
+	i := int(pos)
+	frac := pos - float64(i)
+
+	return Blend(ramp[i], ramp[i+1], frac)
+}
+
+// mixes a into b by t (0 is all a, 1 is all b)
+func Blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+
+	return color.RGBA{
+		mix(a.R, b.R),
+		mix(a.G, b.G),
+		mix(a.B, b.B),
+		mix(a.A, b.A),
+	}
+}
+
+// binary (P5) greyscale PGM
+func EncodePGM(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, "P5\n%d %d\n255\n", bounds.Dx(), bounds.Dy())
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			buf.WriteByte(gray.Y)
+		}
+	}
+
+	return buf.Flush()
+}
+
+// writes a .png or .pgm, depending on the file extension
+func SaveImage(filename string, img image.Image) error {
+	var encode func(w io.Writer, img image.Image) error
+
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".png":
+		encode = png.Encode
+	case ".pgm":
+		encode = EncodePGM
+	default:
+		return fmt.Errorf("unsupported image format %q", ext)
+	}
+
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return err
+	}
+
+	err = encode(file, img)
+
+	// a failed close can mean the image is truncated on disk
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
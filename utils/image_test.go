@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestColorRamp(t *testing.T) {
+	tests := map[float64]color.RGBA{
+		-1:   {0, 0, 0, 255},
+		0:    {0, 0, 0, 255},
+		0.5:  {128, 128, 128, 255},
+		1:    {255, 255, 255, 255},
+		1000: {255, 255, 255, 255},
+	}
+
+	for t1, expected := range tests {
+		val := GrayRamp.At(t1)
+
+		if val != expected {
+			t.Logf("At(%f) should be %v, but got %v", t1, expected, val)
+			t.Fail()
+		}
+	}
+}
+
+func TestEncodePGM(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(1, 0, color.Gray{200})
+
+	var buf bytes.Buffer
+	EncodePGM(&buf, img)
+
+	expected := "P5\n2 1\n255\n\x00\xc8"
+
+	if buf.String() != expected {
+		t.Logf("Answer should be %q, but got %q", expected, buf.String())
+		t.Fail()
+	}
+}
+
+func TestSaveImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	dir := t.TempDir()
+
+	if err := SaveImage(filepath.Join(dir, "img.pgm"), img); err != nil {
+		t.Log("error should be nil", err)
+		t.Fail()
+	}
+
+	// no file gets created for a format we can't write
+	if err := SaveImage(filepath.Join(dir, "img.gif"), img); err == nil {
+		t.Log("expected an error for .gif")
+		t.Fail()
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "img.gif")); err == nil {
+		t.Log("img.gif shouldn't exist")
+		t.Fail()
+	}
+
+	if err := SaveImage(filepath.Join(dir, "missing", "img.png"), img); err == nil {
+		t.Log("expected an error for a missing directory")
+		t.Fail()
+	}
+}
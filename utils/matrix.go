@@ -0,0 +1,103 @@
+package utils
+
+import "math/big"
+
+// square matrix of big integers
+type Matrix [][]*big.Int
+
+func NewMatrix(size int) Matrix {
+	matrix := make(Matrix, size)
+
+	for i := range matrix {
+		matrix[i] = make([]*big.Int, size)
+
+		for j := range matrix[i] {
+			matrix[i][j] = new(big.Int)
+		}
+	}
+
+	return matrix
+}
+
+func Identity(size int) Matrix {
+	matrix := NewMatrix(size)
+
+	for i := range matrix {
+		matrix[i][i].SetInt64(1)
+	}
+
+	return matrix
+}
+
+// reduces x in place, if there's a modulus
+func Reduce(x, modulus *big.Int) *big.Int {
+	if modulus != nil {
+		x.Mod(x, modulus)
+	}
+
+	return x
+}
+
+// a × b, optionally mod modulus (nil for exact)
+func (a Matrix) Multiply(b Matrix, modulus *big.Int) Matrix {
+	out := NewMatrix(len(a))
+	product := new(big.Int)
+
+	for i := range a {
+		for k := range b {
+			if a[i][k].Sign() == 0 {
+				continue
+			}
+
+			for j := range b[k] {
+				product.Mul(a[i][k], b[k][j])
+				out[i][j].Add(out[i][j], product)
+			}
+		}
+
+		for j := range out[i] {
+			Reduce(out[i][j], modulus)
+		}
+	}
+
+	return out
+}
+
+// matrix × column vector
+func (a Matrix) Apply(vec []*big.Int, modulus *big.Int) []*big.Int {
+	out := make([]*big.Int, len(a))
+	product := new(big.Int)
+
+	for i := range a {
+		out[i] = new(big.Int)
+
+		for j, val := range vec {
+			product.Mul(a[i][j], val)
+			out[i].Add(out[i], product)
+		}
+
+		Reduce(out[i], modulus)
+	}
+
+	return out
+}
+
+// exponentiation by squaring: O(log exp) multiplications
+func (a Matrix) Power(exp uint64, modulus *big.Int) Matrix {
+	result := Identity(len(a))
+	base := a
+
+	for exp > 0 {
+		if exp&1 == 1 {
+			result = result.Multiply(base, modulus)
+		}
+
+		exp >>= 1
+
+		if exp > 0 {
+			base = base.Multiply(base, modulus)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	"github.com/bozdoz/advent-of-code-2021/types"
+)
+
+func Sum[T types.Numeric](nums ...T) (s T) {
+	for _, val := range nums {
+		s += val
+	}
+
+	return
+}
+
+func Mean[T types.Numeric](arr []T) float64 {
+	var sum float64
+
+	for _, val := range arr {
+		sum += float64(val)
+	}
+
+	return sum / float64(len(arr))
+}
+
+// weights don't need to add up to 1
+func WeightedMean[T, W types.Numeric](arr []T, weights []W) float64 {
+	if len(arr) != len(weights) {
+		panic("WeightedMean needs one weight per value")
+	}
+
+	var sum, totalWeight float64
+
+	for i, val := range arr {
+		weight := float64(weights[i])
+		sum += float64(val) * weight
+		totalWeight += weight
+	}
+
+	return sum / totalWeight
+}
+
+// the middle value; or the mean of the two middle values
+// if there are an even number of values
+func Median[T types.Numeric](arr []T) float64 {
+	return Quantile(arr, 0.5)
+}
+
+// q is between 0 and 1, and values between two ranks are
+// linearly interpolated (Quantile(arr, 0.5) is the median)
+func Quantile[T types.Numeric](arr []T, q float64) float64 {
+	n := len(arr)
+
+	if n == 0 {
+		panic("cannot get a quantile of an empty slice")
+	}
+
+	if q < 0 || q > 1 {
+		panic("quantile must be between 0 and 1")
+	}
+
+	pos := q * float64(n-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+
+	// selectKth only rearranges values, so one copy is enough for both
+	copied := append([]T{}, arr...)
+	low := float64(selectKth(copied, lower))
+
+	if upper == lower {
+		return low
+	}
+
+	high := float64(selectKth(copied, upper))
+
+	return low + (high-low)*(pos-float64(lower))
+}
+
+// the k-th smallest value (0-indexed) in O(n), without sorting
+// or mutating arr
+func Select[T types.Numeric](arr []T, k int) T {
+	if k < 0 || k >= len(arr) {
+		panic("Select index out of range")
+	}
+
+	return selectKth(append([]T{}, arr...), k)
+}
+
+// median of medians: picking the pivot this way guarantees
+// each round discards at least ~30% of the values, so it's O(n)
+// even in the worst case (rearranges arr)
+func selectKth[T types.Numeric](arr []T, k int) T {
+	for {
+		if len(arr) <= 5 {
+			insertionSort(arr)
+			return arr[k]
+		}
+
+		pivot := medianOfMedians(arr)
+		lt, gt := partition(arr, pivot)
+
+		if k < lt {
+			arr = arr[:lt]
+		} else if k < gt {
+			return pivot
+		} else {
+			arr = arr[gt:]
+			k -= gt
+		}
+	}
+}
+
+// median of each group of 5, then the median of those
+func medianOfMedians[T types.Numeric](arr []T) T {
+	medians := make([]T, 0, (len(arr)+4)/5)
+
+	for i := 0; i < len(arr); i += 5 {
+		end := i + 5
+
+		if end > len(arr) {
+			end = len(arr)
+		}
+
+		group := arr[i:end]
+		insertionSort(group)
+		medians = append(medians, group[len(group)/2])
+	}
+
+	return selectKth(medians, len(medians)/2)
+}
+
+// three-way partition: arr[:lt] < pivot, arr[lt:gt] == pivot, arr[gt:] > pivot
+func partition[T types.Numeric](arr []T, pivot T) (lt, gt int) {
+	i := 0
+	gt = len(arr)
+
+	for i < gt {
+		switch {
+		case arr[i] < pivot:
+			arr[lt], arr[i] = arr[i], arr[lt]
+			lt++
+			i++
+		case arr[i] > pivot:
+			gt--
+			arr[gt], arr[i] = arr[i], arr[gt]
+		default:
+			i++
+		}
+	}
+
+	return
+}
+
+// fastest for tiny slices
+func insertionSort[T types.Numeric](arr []T) {
+	for i := 1; i < len(arr); i++ {
+		for j := i; j > 0 && arr[j] < arr[j-1]; j-- {
+			arr[j], arr[j-1] = arr[j-1], arr[j]
+		}
+	}
+}
+
+// most common value; ties go to the smallest value, so the
+// result doesn't depend on map iteration order (false if arr is empty)
+func Mode[T types.Numeric](arr []T) (T, bool) {
+	if len(arr) == 0 {
+		var zero T
+
+		return zero, false
+	}
+
+	count := map[T]int{}
+
+	for _, val := range arr {
+		count[val]++
+	}
+
+	keys := make([]T, 0, len(count))
+
+	for key := range count {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	maxVal := keys[0]
+
+	for _, key := range keys[1:] {
+		if count[key] > count[maxVal] {
+			maxVal = key
+		}
+	}
+
+	return maxVal, true
+}
+
+// population variance
+func Variance[T types.Numeric](arr []T) float64 {
+	mean := Mean(arr)
+
+	var sum float64
+
+	for _, val := range arr {
+		x := float64(val) - mean
+		sum += x * x
+	}
+
+	return sum / float64(len(arr))
+}
+
+// population standard deviation
+func Stdev[T types.Numeric](arr []T) float64 {
+	return math.Sqrt(Variance(arr))
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestMedianEvenAndOdd(t *testing.T) {
+	tests := []struct {
+		input    []int
+		expected float64
+	}{
+		{[]int{3, 1, 2}, 2},
+		{[]int{4, 1, 3, 2}, 2.5},
+		// min/max midpoint would say 50
+		{[]int{1, 2, 3, 100}, 2.5},
+		{[]int{7}, 7},
+	}
+
+	for _, test := range tests {
+		val := Median(test.input)
+
+		if !almostEqual(val, test.expected) {
+			t.Logf("Median of %v should be %f, but got %f", test.input, test.expected, val)
+			t.Fail()
+		}
+	}
+}
+
+func TestSelectMatchesSort(t *testing.T) {
+	random := rand.New(rand.NewSource(2021))
+	arr := make([]int, 1001)
+
+	for i := range arr {
+		// lots of duplicates
+		arr[i] = random.Intn(50)
+	}
+
+	sorted := append([]int{}, arr...)
+	sort.Ints(sorted)
+
+	for _, k := range []int{0, 1, 250, 500, 999, 1000} {
+		val := Select(arr, k)
+
+		if val != sorted[k] {
+			t.Logf("Select(%d) should be %d, but got %d", k, sorted[k], val)
+			t.Fail()
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	arr := []float64{10, 20, 30, 40, 50}
+
+	tests := map[float64]float64{
+		0:    10,
+		0.25: 20,
+		0.5:  30,
+		0.9:  46,
+		1:    50,
+	}
+
+	for q, expected := range tests {
+		val := Quantile(arr, q)
+
+		if !almostEqual(val, expected) {
+			t.Logf("Quantile(%f) should be %f, but got %f", q, expected, val)
+			t.Fail()
+		}
+	}
+}
+
+func TestModeTieBreak(t *testing.T) {
+	// 3 and 4 both appear 4 times
+	for i := 0; i < 20; i++ {
+		val, ok := Mode(vals)
+
+		if !ok || val != 3 {
+			t.Logf("Mode should be %d, but got %d", 3, val)
+			t.Fail()
+			return
+		}
+	}
+}
+
+func TestModeEmpty(t *testing.T) {
+	val, ok := Mode([]int{})
+
+	if ok || val != 0 {
+		t.Logf("Mode of nothing should be 0, false, but got %d, %v", val, ok)
+		t.Fail()
+	}
+}
+
+func TestWeightedMean(t *testing.T) {
+	expected := 2.5
+	val := WeightedMean([]int{1, 2, 3}, []float64{0.5, 0, 1.5})
+
+	if !almostEqual(val, expected) {
+		t.Logf("Answer should be %f, but got %f", expected, val)
+		t.Fail()
+	}
+}
+
+func TestVarianceAndStdev(t *testing.T) {
+	arr := []int{2, 4, 4, 4, 5, 5, 7, 9}
+
+	if val := Variance(arr); !almostEqual(val, 4) {
+		t.Logf("Variance should be %f, but got %f", 4.0, val)
+		t.Fail()
+	}
+
+	if val := Stdev(arr); !almostEqual(val, 2) {
+		t.Logf("Stdev should be %f, but got %f", 2.0, val)
+		t.Fail()
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"io/ioutil"
 	golog "log"
-	"math"
 	"os"
 	"regexp"
 	"sort"
@@ -82,71 +81,6 @@ func LoadCSVInt(filename string) (out []int) {
 	return
 }
 
-func Sum(nums ...int) (s int) {
-	for _, val := range nums {
-		s += val
-	}
-
-	return
-}
-
-func Mean(arr []int) float64 {
-	n := len(arr)
-
-	sum := Sum(arr...)
-
-	return float64(sum) / float64(n)
-}
-
-func Median(arr []int) float64 {
-	min := arr[0]
-	max := min
-
-	for _, val := range arr {
-		if val < min {
-			min = val
-		} else if val > max {
-			max = val
-		}
-	}
-
-	return float64(max-min)/float64(2) + float64(min)
-}
-
-func Mode(arr []int) int {
-	count := map[int]int{}
-
-	for _, val := range arr {
-		count[val]++
-	}
-
-	maxCount := 0
-	maxVal := arr[0]
-
-	for key, val := range count {
-		if val > maxCount {
-			maxCount = val
-			maxVal = key
-		}
-	}
-
-	return maxVal
-}
-
-func Stdev(vals []int) float64 {
-	mean := Mean(vals)
-	n := len(vals)
-
-	var sum float64
-
-	for _, val := range vals {
-		x := float64(val) - mean
-		sum += x * x
-	}
-
-	return math.Sqrt(sum / float64(n))
-}
-
 // https://golangbyexample.com/sort-string-golang/
 type sortRuneString []rune
 